@@ -1,6 +1,8 @@
 package excelbuilder
 
 import (
+	"fmt"
+	"io"
 	"sync"
 
 	"github.com/xuri/excelize/v2"
@@ -63,6 +65,47 @@ func (eb *ExcelBuilder) NewWorkbook() *WorkbookBuilder {
 	}
 }
 
+// Open opens an existing workbook file and returns a WorkbookBuilder to
+// edit it; GetSheet and AddSheet continue an existing sheet after its last
+// used row. Save the result with Build().Save() or Build().SaveAs(path).
+func Open(path string) (*WorkbookBuilder, error) {
+	return New().OpenWorkbook(path)
+}
+
+// OpenReader reads an existing workbook from r and returns a
+// WorkbookBuilder to edit it
+func OpenReader(r io.Reader) (*WorkbookBuilder, error) {
+	return New().OpenWorkbookReader(r)
+}
+
+// OpenWorkbook opens an existing workbook file in place of the builder's
+// file, e.g. New().WithErrorCollection(true).OpenWorkbook(path)
+func (eb *ExcelBuilder) OpenWorkbook(path string) (*WorkbookBuilder, error) {
+	file, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	return eb.useFile(file), nil
+}
+
+// OpenWorkbookReader reads an existing workbook from r in place of the
+// builder's file
+func (eb *ExcelBuilder) OpenWorkbookReader(r io.Reader) (*WorkbookBuilder, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workbook: %w", err)
+	}
+	return eb.useFile(file), nil
+}
+
+// useFile switches the builder to file and returns its WorkbookBuilder
+func (eb *ExcelBuilder) useFile(file *excelize.File) *WorkbookBuilder {
+	// Cached style IDs belong to the previous file
+	eb.styleManager.ClearCache()
+	eb.file = file
+	return eb.NewWorkbook()
+}
+
 // ConvertCSVData converts CSV data to Excel format
 func (eb *ExcelBuilder) ConvertCSVData(csvData [][]string) *WorkbookBuilder {
 	workbook := eb.NewWorkbook()
@@ -78,22 +121,18 @@ func (eb *ExcelBuilder) ConvertCSVData(csvData [][]string) *WorkbookBuilder {
 	return workbook
 }
 
-// ConvertJSONToWorkbook converts JSON data to Excel workbook
+// ConvertJSONToWorkbook converts the objects of the "data" array of JSON
+// data to rows below a header row with the keys of all objects. Nested
+// arrays of objects are written to child sheets with a parent_id column.
+// Map keys have no order, so columns are sorted by key; ImportHelper.FromJSON
+// keeps the order of a JSON document. Earlier versions wrote each object's
+// values from row 1 in map order, with no header, so callers reading the
+// data must now start at row 2 and find columns by their header.
 func (eb *ExcelBuilder) ConvertJSONToWorkbook(jsonData map[string]interface{}) *WorkbookBuilder {
 	workbook := eb.NewWorkbook()
-	sheet := workbook.AddSheet("Sheet1")
 
-	// Simple JSON to Excel conversion
-	if data, ok := jsonData["data"].([]interface{}); ok {
-		for _, item := range data {
-			if itemMap, ok := item.(map[string]interface{}); ok {
-				rowBuilder := sheet.AddRow()
-				for _, value := range itemMap {
-					rowBuilder.AddCell(value)
-				}
-			}
-		}
-	}
+	data, _ := jsonData["data"].([]interface{})
+	writeJSONRecords(workbook, newJSONSheetNames(), "Sheet1", data)
 
 	return workbook
 }
@@ -121,43 +160,52 @@ func (eb *ExcelBuilder) GetCollectedErrors() []error {
 	return errorsCopy
 }
 
-// TransformDataToPivot transforms raw data to pivot format
+// TransformDataToPivot aggregates raw data into a crosstab (see
+// DataTransformer.PivotData) and writes it as a styled static table.
 func (eb *ExcelBuilder) TransformDataToPivot(rawData []map[string]interface{}, config PivotConfig, sheetName string) *WorkbookBuilder {
-	// Simple pivot transformation
 	workbook := eb.NewWorkbook()
 	sheet := workbook.AddSheet(sheetName)
 
-	// Add headers based on pivot configuration
-	headerRow := sheet.AddRow()
-	for _, field := range config.RowFields {
-		headerRow.AddCell(field)
-	}
-	for _, field := range config.ColumnFields {
-		headerRow.AddCell(field)
-	}
-	for _, field := range config.ValueFields {
-		headerRow.AddCell(field)
+	result, err := computePivot(rawData, config)
+	if err != nil {
+		eb.AddError(fmt.Errorf("failed to pivot data: %w", err))
+		return workbook
 	}
 
-	// Add data (simplified)
-	for _, row := range rawData {
-		dataRow := sheet.AddRow()
-		for _, field := range config.RowFields {
-			if value, ok := row[field]; ok {
-				dataRow.AddCell(value)
-			}
-		}
-		for _, field := range config.ColumnFields {
-			if value, ok := row[field]; ok {
-				dataRow.AddCell(value)
-			}
-		}
-		for _, field := range config.ValueFields {
-			if value, ok := row[field]; ok {
-				dataRow.AddCell(value)
+	for i, rowData := range result.rows {
+		style, styled := pivotRowStyle(result.kinds[i])
+		row := sheet.AddRow()
+		for _, value := range rowData {
+			cell := row.AddCell(value)
+			if styled {
+				cell.WithStyle(style)
 			}
 		}
 	}
 
 	return workbook
 }
+
+// pivotRowStyle returns the style of a static pivot table row
+func pivotRowStyle(kind int) (StyleConfig, bool) {
+	switch kind {
+	case pivotRowHeader:
+		return StyleConfig{
+			Font:   FontConfig{Bold: true, Color: "#FFFFFF"},
+			Fill:   FillConfig{Type: "pattern", Color: "#4472C4"},
+			Border: BorderConfig{Bottom: BorderSide{Style: "thin", Color: "#000000"}},
+		}, true
+	case pivotRowSubtotal:
+		return StyleConfig{
+			Font: FontConfig{Bold: true},
+			Fill: FillConfig{Type: "pattern", Color: "#D9E1F2"},
+		}, true
+	case pivotRowGrandTotal:
+		return StyleConfig{
+			Font:   FontConfig{Bold: true},
+			Border: BorderConfig{Top: BorderSide{Style: "double", Color: "#000000"}},
+		}, true
+	default:
+		return StyleConfig{}, false
+	}
+}
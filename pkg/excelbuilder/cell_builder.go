@@ -55,42 +55,7 @@ func (cb *CellBuilder) WithDataValidation(dvc *DataValidationConfig) *CellBuilde
 		return cb
 	}
 
-	dv := excelize.NewDataValidation(true)
-	dv.SetSqref(cb.cellRef)
-	dv.AllowBlank = dvc.AllowBlank
-	dv.ShowInputMessage = dvc.ShowInputMessage
-	dv.ShowErrorMessage = dvc.ShowErrorMessage
-	dv.ErrorTitle = &dvc.ErrorTitle
-	dv.Error = &dvc.ErrorBody
-	if dvc.ErrorStyle != "" {
-		dv.ErrorStyle = &dvc.ErrorStyle
-	}
-	dv.PromptTitle = &dvc.PromptTitle
-	dv.Prompt = &dvc.PromptBody
-
-	switch dvc.Type {
-	case "list":
-		_ = dv.SetDropList(dvc.Formula1)
-	case "whole", "decimal", "date", "time", "text_length":
-		var f1, f2 interface{}
-		if len(dvc.Formula1) > 0 {
-			f1 = dvc.Formula1[0]
-		}
-		if len(dvc.Formula2) > 0 {
-			f2 = dvc.Formula2[0]
-		}
-		_ = dv.SetRange(f1, f2, getValidationType(dvc.Type), getOperatorType(dvc.Operator))
-	case "custom":
-		var f1, f2 string
-		if len(dvc.Formula1) > 0 {
-			f1 = dvc.Formula1[0]
-		}
-		if len(dvc.Formula2) > 0 {
-			f2 = dvc.Formula2[0]
-		}
-		dv.Formula1 = f1
-		dv.Formula2 = f2
-	}
+	dv := newDataValidation(dvc, cb.cellRef)
 
 	err := cb.sheetBuilder.workbookBuilder.file.AddDataValidation(cb.sheetBuilder.sheetName, dv)
 	if err != nil {
@@ -188,6 +153,47 @@ func (cb *CellBuilder) Done() *RowBuilder {
 	return cb.rowBuilder
 }
 
+// newDataValidation converts a validation configuration for the cells of sqref
+func newDataValidation(dvc *DataValidationConfig, sqref string) *excelize.DataValidation {
+	dv := excelize.NewDataValidation(true)
+	dv.SetSqref(sqref)
+	dv.AllowBlank = dvc.AllowBlank
+	dv.ShowInputMessage = dvc.ShowInputMessage
+	dv.ShowErrorMessage = dvc.ShowErrorMessage
+	dv.ErrorTitle = &dvc.ErrorTitle
+	dv.Error = &dvc.ErrorBody
+	if dvc.ErrorStyle != "" {
+		dv.ErrorStyle = &dvc.ErrorStyle
+	}
+	dv.PromptTitle = &dvc.PromptTitle
+	dv.Prompt = &dvc.PromptBody
+
+	switch dvc.Type {
+	case "list":
+		_ = dv.SetDropList(dvc.Formula1)
+	case "whole", "decimal", "date", "time", "text_length":
+		var f1, f2 interface{}
+		if len(dvc.Formula1) > 0 {
+			f1 = dvc.Formula1[0]
+		}
+		if len(dvc.Formula2) > 0 {
+			f2 = dvc.Formula2[0]
+		}
+		_ = dv.SetRange(f1, f2, getValidationType(dvc.Type), getOperatorType(dvc.Operator))
+	case "custom":
+		var f1, f2 string
+		if len(dvc.Formula1) > 0 {
+			f1 = dvc.Formula1[0]
+		}
+		if len(dvc.Formula2) > 0 {
+			f2 = dvc.Formula2[0]
+		}
+		dv.Formula1 = f1
+		dv.Formula2 = f2
+	}
+	return dv
+}
+
 // Helper functions for data validation
 func getErrorStyle(style string) excelize.DataValidationErrorStyle {
 	switch style {
@@ -1,15 +1,22 @@
 package excelbuilder
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/xuri/excelize/v2"
 )
 
 // ChartBuilder handles chart creation and configuration
 type ChartBuilder struct {
-	file      *excelize.File
-	sheetName string
-	config    ChartConfig
-	cell      string // Position where chart will be placed
+	file       *excelize.File
+	sheetName  string
+	config     ChartConfig
+	cell       string // Position where chart will be placed
+	format     *ChartFormat
+	anchor     string // Cell range the chart is anchored to (two-cell anchor)
+	chartSheet bool   // Whether the chart is built as a dedicated chart sheet
+	err        error
 }
 
 // NewChartBuilder creates a new ChartBuilder instance
@@ -77,8 +84,37 @@ func (cb *ChartBuilder) SetPosition(cell string) *ChartBuilder {
 	return cb
 }
 
+// WithFormat sets the scaling, offset, printing and locking options of the chart.
+func (cb *ChartBuilder) WithFormat(format ChartFormat) *ChartBuilder {
+	cb.format = &format
+	return cb
+}
+
+// SetAnchorRange anchors the chart to a cell range (e.g. "B2:H20").
+// The chart fills the range and moves and resizes with its rows and columns,
+// overriding any dimensions set with SetDimensions and the scale and offsets
+// set with WithFormat.
+func (cb *ChartBuilder) SetAnchorRange(cellRange string) *ChartBuilder {
+	cb.anchor = cellRange
+	return cb
+}
+
 // Build creates the chart and adds it to the sheet.
 func (cb *ChartBuilder) Build() error {
+	if cb.err != nil {
+		return cb.err
+	}
+
+	cell := cb.cell
+	width, height := cb.config.Width, cb.config.Height
+	if cb.anchor != "" {
+		startCell, w, h, err := cb.anchorDimensions()
+		if err != nil {
+			return err
+		}
+		cell, width, height = startCell, w, h
+	}
+
 	var series []excelize.ChartSeries
 	for _, s := range cb.config.DataSeries {
 		series = append(series, excelize.ChartSeries{
@@ -92,9 +128,10 @@ func (cb *ChartBuilder) Build() error {
 	chartOptions := &excelize.Chart{
 		Type: mapChartType(cb.config.Type),
 		Dimension: excelize.ChartDimension{
-			Width:  uint(cb.config.Width),
-			Height: uint(cb.config.Height),
+			Width:  uint(width),
+			Height: uint(height),
 		},
+		Format: cb.buildGraphicOptions(),
 		Title: []excelize.RichTextRun{
 			{
 				Text: cb.config.Title,
@@ -107,7 +144,62 @@ func (cb *ChartBuilder) Build() error {
 		Series: series,
 	}
 
-	return cb.file.AddChart(cb.sheetName, cb.cell, chartOptions)
+	if cb.chartSheet {
+		return cb.file.AddChartSheet(cb.sheetName, chartOptions)
+	}
+	return cb.file.AddChart(cb.sheetName, cell, chartOptions)
+}
+
+// buildGraphicOptions converts the chart format to excelize GraphicOptions
+func (cb *ChartBuilder) buildGraphicOptions() excelize.GraphicOptions {
+	opts := excelize.GraphicOptions{}
+	if cb.format != nil {
+		opts.ScaleX = cb.format.XScale
+		opts.ScaleY = cb.format.YScale
+		opts.OffsetX = int(cb.format.XOffset)
+		opts.OffsetY = int(cb.format.YOffset)
+		// Left nil, excelize applies its defaults
+		opts.PrintObject = cb.format.PrintObj
+		opts.Locked = cb.format.Locked
+		opts.LockAspectRatio = cb.format.LockRatio
+	}
+	if cb.anchor != "" {
+		// The chart already spans the anchor range, so it must not be scaled
+		// or moved again and should follow the cells when they are resized.
+		opts.ScaleX = 1
+		opts.ScaleY = 1
+		opts.OffsetX = 0
+		opts.OffsetY = 0
+		opts.Positioning = "twoCell"
+	}
+	return opts
+}
+
+// anchorDimensions returns the top-left cell and the pixel size of the anchor range
+func (cb *ChartBuilder) anchorDimensions() (string, int, int, error) {
+	startCol, startRow, endCol, endRow, err := parseCellRange(cb.anchor)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("invalid anchor range '%s': %w", cb.anchor, err)
+	}
+
+	var width, height int
+	for col := startCol; col <= endCol; col++ {
+		colName, _ := excelize.ColumnNumberToName(col)
+		colWidth, err := cb.file.GetColWidth(cb.sheetName, colName)
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("failed to get width of column %s: %w", colName, err)
+		}
+		width += colWidthToPixels(colWidth)
+	}
+	for row := startRow; row <= endRow; row++ {
+		rowHeight, err := cb.file.GetRowHeight(cb.sheetName, row)
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("failed to get height of row %d: %w", row, err)
+		}
+		height += rowHeightToPixels(rowHeight)
+	}
+	startCell, _ := excelize.CoordinatesToCellName(startCol, startRow)
+	return startCell, width, height, nil
 }
 
 // mapChartType converts a string representation of a chart type to the excelize constant.
@@ -132,3 +224,29 @@ func mapChartType(chartType string) excelize.ChartType {
 func (cb *ChartBuilder) GetConfig() ChartConfig {
 	return cb.config
 }
+
+// Default sizes excelize assumes when a column width or row height is not set.
+const (
+	defaultColWidth        = 9.140625
+	defaultColWidthPixels  = 64
+	defaultRowHeight       = 15
+	defaultRowHeightPixels = 20
+)
+
+// colWidthToPixels converts a column width in characters to pixels the same
+// way excelize does when positioning drawings.
+func colWidthToPixels(width float64) int {
+	if width == defaultColWidth {
+		return defaultColWidthPixels
+	}
+	return int(width*8 + 0.5)
+}
+
+// rowHeightToPixels converts a row height in points to pixels the same way
+// excelize does when positioning drawings.
+func rowHeightToPixels(height float64) int {
+	if height == defaultRowHeight {
+		return defaultRowHeightPixels
+	}
+	return int(math.Ceil(4.0 / 3.4 * height))
+}
@@ -0,0 +1,342 @@
+package excelbuilder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/language"
+	"golang.org/x/text/transform"
+)
+
+// csvSniffSize is the number of bytes inspected to detect the encoding and
+// the delimiter of CSV data
+const csvSniffSize = 64 << 10
+
+// csvSniffLines is the number of lines compared to detect the delimiter
+const csvSniffLines = 10
+
+// csvNumberPattern matches normalized numbers
+var csvNumberPattern = regexp.MustCompile(`^[+-]?\d+(\.\d+)?%?$`)
+
+// csvDelimiters are the delimiters recognized when sniffing, by preference
+var csvDelimiters = []rune{',', ';', '\t', '|'}
+
+// newCSVReader returns a CSV reader for r configured with options. The
+// input is decoded to UTF-8 and its delimiter is sniffed unless set.
+// Records may have different numbers of fields.
+func newCSVReader(r io.Reader, options CSVOptions) (*csv.Reader, error) {
+	decoded, err := decodeCSV(r, options.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	buffered := bufio.NewReaderSize(decoded, csvSniffSize)
+	delimiter, _ := utf8.DecodeRuneInString(options.Delimiter)
+	if options.Delimiter == "" {
+		sample, err := buffered.Peek(csvSniffSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		delimiter = sniffCSVDelimiter(sample, options)
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = delimiter
+	if options.Comment != 0 {
+		reader.Comment = options.Comment
+	}
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	return reader, nil
+}
+
+// decodeCSV returns r decoded from the named encoding to UTF-8. The
+// encoding is detected when name is empty or "auto". A byte order mark
+// always takes precedence and is removed.
+func decodeCSV(r io.Reader, name string) (io.Reader, error) {
+	buffered := bufio.NewReaderSize(r, csvSniffSize)
+
+	var enc encoding.Encoding
+	if name == "" || strings.EqualFold(name, "auto") {
+		sample, err := buffered.Peek(csvSniffSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		enc = detectCSVEncoding(sample)
+	} else {
+		var err error
+		if enc, err = htmlindex.Get(name); err != nil {
+			return nil, fmt.Errorf("unsupported CSV encoding '%s': %w", name, err)
+		}
+	}
+	return transform.NewReader(buffered, unicode.BOMOverride(enc.NewDecoder())), nil
+}
+
+// detectCSVEncoding guesses the encoding of the start of CSV data:
+// UTF-16 when it has a byte order mark or NUL bytes, UTF-8 when it is valid
+// UTF-8, Shift-JIS when its bytes look like Japanese text and Windows-1252
+// otherwise
+func detectCSVEncoding(sample []byte) encoding.Encoding {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	}
+
+	// ASCII text encoded as UTF-16 has a NUL byte in every character
+	if i := bytes.IndexByte(sample, 0); i != -1 {
+		if i%2 == 0 {
+			return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+		}
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	}
+
+	// The sample may end in the middle of a character
+	valid := sample
+	for i := 0; i < utf8.UTFMax-1 && len(valid) > 0 && !utf8.Valid(valid); i++ {
+		valid = valid[:len(valid)-1]
+	}
+	if utf8.Valid(valid) {
+		return unicode.UTF8
+	}
+	if isShiftJIS(sample) {
+		return japanese.ShiftJIS
+	}
+	return charmap.Windows1252
+}
+
+// isShiftJIS reports whether every non-ASCII byte of sample belongs to a
+// Shift-JIS character and at least one of them is a double byte character
+// that would be unusual in Windows-1252 text
+func isShiftJIS(sample []byte) bool {
+	japanese := false
+	for i := 0; i < len(sample); i++ {
+		lead := sample[i]
+		switch {
+		case lead < 0x80, lead >= 0xA1 && lead <= 0xDF:
+			// ASCII or half-width katakana
+		case lead >= 0x81 && lead <= 0x9F, lead >= 0xE0 && lead <= 0xFC:
+			if i+1 == len(sample) {
+				return japanese
+			}
+			trail := sample[i+1]
+			if trail < 0x40 || trail == 0x7F || trail > 0xFC {
+				return false
+			}
+			// Hiragana, katakana and most kanji start with these bytes,
+			// which are punctuation or unused in Windows-1252
+			if lead <= 0x9F && trail >= 0x80 {
+				japanese = true
+			}
+			i++
+		default:
+			return false
+		}
+	}
+	return japanese
+}
+
+// sniffCSVDelimiter returns the delimiter of the first lines of sample: the
+// candidate found the same number of times on every line, preferring the
+// most frequent. The decimal separator of options.Locale is never a
+// candidate. Lines skipped by options are ignored.
+func sniffCSVDelimiter(sample []byte, options CSVOptions) rune {
+	// An invalid locale is reported by the caller
+	numbers, _ := newCSVNumberFormat(options.Locale)
+	var candidates []rune
+	for _, delimiter := range csvDelimiters {
+		if delimiter != numbers.decimal {
+			candidates = append(candidates, delimiter)
+		}
+	}
+
+	var lines []string
+	for _, line := range splitCSVLines(string(sample)) {
+		if len(lines) == options.SkipRows+csvSniffLines {
+			break
+		}
+		if strings.TrimSpace(line) == "" || (options.Comment != 0 && strings.HasPrefix(line, string(options.Comment))) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) <= options.SkipRows {
+		return candidates[0]
+	}
+	lines = lines[options.SkipRows:]
+
+	best, bestCount, bestConsistent := candidates[0], 0, false
+	for _, delimiter := range candidates {
+		count := countUnquoted(lines[0], delimiter)
+		if count == 0 {
+			continue
+		}
+		consistent := true
+		for _, line := range lines[1:] {
+			if countUnquoted(line, delimiter) != count {
+				consistent = false
+				break
+			}
+		}
+		if (consistent && !bestConsistent) || (consistent == bestConsistent && count > bestCount) {
+			best, bestCount, bestConsistent = delimiter, count, consistent
+		}
+	}
+	return best
+}
+
+// splitCSVLines splits data into lines, keeping line breaks inside quoted
+// fields. The last line is dropped when data does not end with a line break
+// because it may be incomplete.
+func splitCSVLines(data string) []string {
+	var lines []string
+	start, quoted := 0, false
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '"':
+			quoted = !quoted
+		case '\n':
+			if !quoted {
+				lines = append(lines, strings.TrimSuffix(data[start:i], "\r"))
+				start = i + 1
+			}
+		}
+	}
+	if start == 0 && len(data) > 0 {
+		lines = append(lines, data)
+	}
+	return lines
+}
+
+// countUnquoted counts the occurrences of r outside quoted fields of line
+func countUnquoted(line string, r rune) int {
+	count, quoted := 0, false
+	for _, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == r && !quoted:
+			count++
+		}
+	}
+	return count
+}
+
+// csvNumberFormat holds the separators of numbers in CSV data
+type csvNumberFormat struct {
+	decimal rune
+	groups  string // Accepted digit group separators
+}
+
+// Digit separators by language, for languages that do not write 1,234.56
+var (
+	decimalCommaDotGroups   = []string{"da", "de", "el", "es", "hr", "id", "it", "nl", "pt", "ro", "sl", "sr", "tr", "vi"}
+	decimalCommaSpaceGroups = []string{"bg", "cs", "et", "fi", "fr", "hu", "lt", "lv", "nb", "no", "pl", "ru", "sk", "sv", "uk"}
+)
+
+// newCSVNumberFormat returns the number format of a locale such as "de-DE".
+// An empty locale writes numbers as 1,234.56.
+func newCSVNumberFormat(locale string) (csvNumberFormat, error) {
+	format := csvNumberFormat{decimal: '.', groups: ","}
+	if locale == "" {
+		return format, nil
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return format, fmt.Errorf("invalid CSV locale '%s': %w", locale, err)
+	}
+	base, _ := tag.Base()
+	switch {
+	case containsString(decimalCommaDotGroups, base.String()):
+		format = csvNumberFormat{decimal: ',', groups: "."}
+	case containsString(decimalCommaSpaceGroups, base.String()):
+		format = csvNumberFormat{decimal: ',', groups: " \u00a0\u202f"}
+	}
+	return format, nil
+}
+
+// normalize rewrites a number written in the format, optionally signed or
+// followed by a percent sign, so that strconv can parse it. Other values
+// are returned unchanged.
+func (nf csvNumberFormat) normalize(value string) string {
+	number := strings.TrimSpace(value)
+	number, percent := strings.CutSuffix(number, "%")
+	sign := ""
+	if strings.HasPrefix(number, "-") || strings.HasPrefix(number, "+") {
+		sign, number = number[:1], number[1:]
+	}
+
+	integer, fraction, hasFraction := strings.Cut(number, string(nf.decimal))
+	if hasFraction && !isDigits(fraction) {
+		return value
+	}
+	var groups []string
+	start := 0
+	for i, r := range integer {
+		if strings.ContainsRune(nf.groups, r) {
+			groups = append(groups, integer[start:i])
+			start = i + utf8.RuneLen(r)
+		}
+	}
+	groups = append(groups, integer[start:])
+	for i, group := range groups {
+		if !isDigits(group) || (i > 0 && len(group) != 3) || (len(groups) > 1 && len(group) > 3) {
+			return value
+		}
+	}
+
+	normalized := sign + strings.Join(groups, "")
+	if hasFraction {
+		normalized += "." + fraction
+	}
+	if percent {
+		normalized += "%"
+	}
+	return normalized
+}
+
+// parseCSVNumber returns value as an int64 or float64 when it is a number
+// in the format, optionally followed by a percent sign. Numbers with
+// leading zeros and other values are returned unchanged.
+func parseCSVNumber(value string, numbers csvNumberFormat) interface{} {
+	number := numbers.normalize(value)
+	if !csvNumberPattern.MatchString(number) || leadingZeroPattern.MatchString(number) {
+		return value
+	}
+	if percent, ok := strings.CutSuffix(number, "%"); ok {
+		parsed, _ := strconv.ParseFloat(percent, 64)
+		return parsed / 100
+	}
+	if parsed, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return parsed
+	}
+	parsed, _ := strconv.ParseFloat(number, 64)
+	return parsed
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
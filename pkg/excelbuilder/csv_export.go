@@ -0,0 +1,159 @@
+package excelbuilder
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// utf8BOM is the byte order mark written before UTF-8 CSV output
+const utf8BOM = "\xEF\xBB\xBF"
+
+// ToCSV exports the active sheet to a CSV file with displayed values
+func (eh *ExportHelper) ToCSV(filename string) error {
+	return eh.ToCSVWithOptions(filename, CSVOptions{})
+}
+
+// ToCSVWithOptions exports the active sheet to a CSV file with custom options
+func (eh *ExportHelper) ToCSVWithOptions(filename string, options CSVOptions) error {
+	if eh.file == nil {
+		return fmt.Errorf("no Excel file set for export")
+	}
+	sheetName := eh.file.GetSheetName(eh.file.GetActiveSheetIndex())
+	return writeCSVFile(filename, func(w io.Writer) error {
+		return eh.WriteCSV(w, sheetName, options)
+	})
+}
+
+// WriteCSV writes a sheet as CSV. Cells show their displayed (number
+// formatted) values unless options.RawValues is set; formula cells show
+// their last calculated value unless options.Formulas is set. Trailing
+// empty rows are omitted.
+func (eh *ExportHelper) WriteCSV(w io.Writer, sheetName string, options CSVOptions) error {
+	if eh.file == nil {
+		return fmt.Errorf("no Excel file set for export")
+	}
+	index, err := eh.file.GetSheetIndex(sheetName)
+	if err != nil {
+		return err
+	}
+	if index == -1 {
+		return fmt.Errorf("sheet '%s' does not exist", sheetName)
+	}
+
+	if options.BOM {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = options.UseCRLF
+	if options.Delimiter != "" {
+		delimiter, _ := utf8.DecodeRuneInString(options.Delimiter)
+		writer.Comma = delimiter
+	}
+
+	rows, err := eh.file.Rows(sheetName)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// Empty rows are held back until a row with values follows them
+	rowNumber, pendingEmpty := 0, 0
+	for rows.Next() {
+		rowNumber++
+		record, err := rows.Columns(excelize.Options{RawCellValue: options.RawValues})
+		if err != nil {
+			return err
+		}
+		if options.Formulas {
+			if record, err = eh.withFormulas(sheetName, rowNumber, record); err != nil {
+				return err
+			}
+		}
+		if len(record) == 0 {
+			pendingEmpty++
+			continue
+		}
+		for ; pendingEmpty > 0; pendingEmpty-- {
+			if err := writer.Write(nil); err != nil {
+				return err
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	if err := rows.Error(); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteCSVZip writes every worksheet as a CSV file named after the sheet
+// into a zip archive. Chart sheets are skipped.
+func (eh *ExportHelper) WriteCSVZip(w io.Writer, options CSVOptions) error {
+	if eh.file == nil {
+		return fmt.Errorf("no Excel file set for export")
+	}
+
+	archive := zip.NewWriter(w)
+	for _, sheetName := range eh.file.GetSheetList() {
+		// excelize refuses to read the properties of chart sheets, which
+		// have no cells to export
+		if _, err := eh.file.GetSheetProps(sheetName); err != nil {
+			continue
+		}
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     sheetName + ".csv",
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add sheet %s to archive: %w", sheetName, err)
+		}
+		if err := eh.WriteCSV(entry, sheetName, options); err != nil {
+			return fmt.Errorf("failed to export sheet %s: %w", sheetName, err)
+		}
+	}
+	return archive.Close()
+}
+
+// withFormulas replaces the values of the formula cells of a row with
+// their formulas
+func (eh *ExportHelper) withFormulas(sheetName string, rowNumber int, record []string) ([]string, error) {
+	for col := range record {
+		cellRef, _ := excelize.CoordinatesToCellName(col+1, rowNumber)
+		formula, err := eh.file.GetCellFormula(sheetName, cellRef)
+		if err != nil {
+			return nil, err
+		}
+		if formula != "" {
+			record[col] = "=" + formula
+		}
+	}
+	return record, nil
+}
+
+// writeCSVFile creates filename and writes it with write
+func writeCSVFile(filename string, write func(w io.Writer) error) error {
+	outFile, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	if err := write(outFile); err != nil {
+		outFile.Close()
+		return err
+	}
+	return outFile.Close()
+}
@@ -0,0 +1,262 @@
+package excelbuilder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// defaultCSVSampleRows is the number of rows sampled to infer column types
+const defaultCSVSampleRows = 100
+
+// leadingZeroPattern matches numbers whose leading zeros are significant,
+// such as zip codes and account numbers
+var leadingZeroPattern = regexp.MustCompile(`^[+-]?0\d`)
+
+// csvColumnFormats maps inferred column types to their number format.
+// Floats and booleans keep the General format.
+var csvColumnFormats = map[string]excelize.Style{
+	"integer":    {NumFmt: 1},  // 0
+	"percentage": {NumFmt: 10}, // 0.00%
+	"text":       {NumFmt: 49}, // @
+	"currency":   {CustomNumFmt: stringPtr("$#,##0.00")},
+	"date":       {CustomNumFmt: stringPtr("yyyy-mm-dd")},
+	"datetime":   {CustomNumFmt: stringPtr("yyyy-mm-dd hh:mm:ss")},
+}
+
+// AddSheetFromCSV streams CSV data from r into a new sheet without loading
+// it into memory. The type of each column is inferred from the first
+// options.SampleRows rows with DataTypeHandler.InferDataType and then
+// locked: integer, float, currency, percentage, date, datetime and boolean
+// columns are written as typed values with a matching number format, and
+// numbers with leading zeros make a column text. Values that do not match
+// the type of their column are written as text. On error no sheet is
+// added.
+func (wb *WorkbookBuilder) AddSheetFromCSV(name string, r io.Reader, options CSVOptions) (err error) {
+	if err := validateSheetName(name); err != nil {
+		return err
+	}
+	if index, _ := wb.file.GetSheetIndex(name); index != -1 {
+		return fmt.Errorf("sheet '%s' already exists", name)
+	}
+	numbers, err := newCSVNumberFormat(options.Locale)
+	if err != nil {
+		return err
+	}
+	reader, err := newCSVReader(r, options)
+	if err != nil {
+		return err
+	}
+
+	if _, err := wb.file.NewSheet(name); err != nil {
+		return fmt.Errorf("failed to create sheet '%s': %w", name, err)
+	}
+	// Leave no partial sheet behind so that a failed call can be retried
+	defer func() {
+		if err == nil {
+			return
+		}
+		if deleteErr := wb.file.DeleteSheet(name); deleteErr != nil {
+			err = errors.Join(err, deleteErr)
+		}
+	}()
+	for i := 0; i < options.SkipRows; i++ {
+		if _, err := reader.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+	}
+
+	var header []string
+	if options.HasHeader {
+		record, err := reader.Read()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read CSV header: %w", err)
+		}
+		header = append(header, record...)
+	}
+
+	sampleSize := options.SampleRows
+	if sampleSize <= 0 {
+		sampleSize = defaultCSVSampleRows
+	}
+	var sample [][]string
+	for len(sample) < sampleSize {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+		sample = append(sample, append([]string(nil), record...))
+	}
+	columnTypes := inferCSVColumnTypes(sample, numbers)
+
+	sw, err := wb.file.NewStreamWriter(name)
+	if err != nil {
+		return err
+	}
+	// Every value carries the style of its column. Column styles are not
+	// used because the stream writer applies the style of the first column
+	// of a row to all of its unstyled cells.
+	columnStyles := make([]int, len(columnTypes))
+	for i, columnType := range columnTypes {
+		format, ok := csvColumnFormats[columnType]
+		if !ok {
+			continue
+		}
+		if columnStyles[i], err = wb.file.NewStyle(&format); err != nil {
+			return err
+		}
+	}
+
+	row := 0
+	writeRow := func(values []interface{}) error {
+		row++
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		return sw.SetRow(cell, values)
+	}
+
+	if header != nil {
+		headerStyle, err := wb.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+		if err != nil {
+			return err
+		}
+		values := make([]interface{}, len(header))
+		for i, title := range header {
+			values[i] = excelize.Cell{StyleID: headerStyle, Value: title}
+		}
+		if err := writeRow(values); err != nil {
+			return err
+		}
+	}
+	for _, record := range sample {
+		if err := writeRow(convertCSVRecord(record, columnTypes, columnStyles, numbers)); err != nil {
+			return err
+		}
+	}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if err := writeRow(convertCSVRecord(record, columnTypes, columnStyles, numbers)); err != nil {
+			return err
+		}
+	}
+	return sw.Flush()
+}
+
+// StreamCSVToExcel converts CSV data from r to a workbook written to w,
+// streaming the rows as described in WorkbookBuilder.AddSheetFromCSV
+func (ih *ImportHelper) StreamCSVToExcel(r io.Reader, w io.Writer, options CSVOptions) error {
+	workbook := New().NewWorkbook()
+	file := workbook.Build()
+	defer file.Close()
+
+	if err := workbook.AddSheetFromCSV(ih.sheetName, r, options); err != nil {
+		return err
+	}
+	if ih.sheetName != "Sheet1" {
+		if err := file.DeleteSheet("Sheet1"); err != nil {
+			return err
+		}
+	}
+	return file.Write(w)
+}
+
+// inferCSVColumnTypes returns the type of every column of the sample.
+// Empty values are ignored; columns without values are text.
+func inferCSVColumnTypes(sample [][]string, numbers csvNumberFormat) []string {
+	handler := NewDataTypeHandler()
+	var columnTypes []string
+	for _, record := range sample {
+		for col, value := range record {
+			if col >= len(columnTypes) {
+				columnTypes = append(columnTypes, "")
+			}
+			value = strings.TrimSpace(numbers.normalize(value))
+			if value == "" {
+				continue
+			}
+			valueType := "text"
+			if !leadingZeroPattern.MatchString(value) {
+				_, valueType = handler.InferDataType(value)
+			}
+			columnTypes[col] = mergeCSVColumnType(columnTypes[col], valueType)
+		}
+	}
+	for col, columnType := range columnTypes {
+		if columnType == "" {
+			columnTypes[col] = "text"
+		}
+	}
+	return columnTypes
+}
+
+// mergeCSVColumnType combines the type inferred so far for a column with
+// the type of another of its values
+func mergeCSVColumnType(current, next string) string {
+	numeric := map[string]bool{"integer": true, "float": true, "currency": true}
+	switch {
+	case current == "" || current == next:
+		return next
+	case numeric[current] && numeric[next]:
+		if current == "currency" || next == "currency" {
+			return "currency"
+		}
+		return "float"
+	case (current == "date" && next == "datetime") || (current == "datetime" && next == "date"):
+		return "datetime"
+	default:
+		return "text"
+	}
+}
+
+// convertCSVRecord converts the values of a record to the types of their
+// columns and styles them. Values that do not match are kept as text.
+func convertCSVRecord(record []string, columnTypes []string, columnStyles []int, numbers csvNumberFormat) []interface{} {
+	values := make([]interface{}, len(record))
+	for col, value := range record {
+		if value == "" {
+			continue
+		}
+		if col >= len(columnTypes) {
+			values[col] = value
+			continue
+		}
+		values[col] = excelize.Cell{StyleID: columnStyles[col], Value: convertCSVValue(value, columnTypes[col], numbers)}
+	}
+	return values
+}
+
+// convertCSVValue converts a value to a column type, or returns it as is.
+// Date and datetime columns take values of either type.
+func convertCSVValue(value, columnType string, numbers csvNumberFormat) interface{} {
+	trimmed := strings.TrimSpace(numbers.normalize(value))
+	dataTypes := []string{columnType}
+	if columnType == "date" || columnType == "datetime" {
+		dataTypes = []string{"date", "datetime"}
+	}
+	for _, dataType := range dataTypes {
+		if converted, ok := parseDataType(trimmed, dataType); ok {
+			return converted
+		}
+	}
+	return value
+}
+
+// stringPtr returns a pointer to s
+func stringPtr(s string) *string {
+	return &s
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -110,6 +111,15 @@ func (dth *DataTypeHandler) flattenStruct(value interface{}) string {
 	return "{" + strings.Join(parts, ", ") + "}"
 }
 
+// inferredDataTypes are the data types InferDataType tries, in order
+var inferredDataTypes = []string{"boolean", "integer", "float", "date", "datetime", "currency", "percentage"}
+
+// dataTypeLayouts are the layouts of text dates and times
+var dataTypeLayouts = map[string]string{
+	"date":     "2006-01-02",
+	"datetime": "2006-01-02 15:04:05",
+}
+
 // InferDataType attempts to infer the data type from a string value
 func (dth *DataTypeHandler) InferDataType(value string) (interface{}, string) {
 	value = strings.TrimSpace(value)
@@ -118,50 +128,56 @@ func (dth *DataTypeHandler) InferDataType(value string) (interface{}, string) {
 		return "", "string"
 	}
 
-	// Try boolean
-	if strings.ToLower(value) == "true" {
-		return true, "boolean"
-	}
-	if strings.ToLower(value) == "false" {
-		return false, "boolean"
-	}
-
-	// Try integer
-	if intVal, err := strconv.ParseInt(value, 10, 64); err == nil {
-		return intVal, "integer"
-	}
-
-	// Try float
-	if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
-		return floatVal, "float"
+	for _, dataType := range inferredDataTypes {
+		// Only values with a currency symbol are currencies
+		if dataType == "currency" && !strings.HasPrefix(value, "$") {
+			continue
+		}
+		if parsed, ok := parseDataType(value, dataType); ok {
+			return parsed, dataType
+		}
 	}
 
-	// Try date
-	if dateVal, err := time.Parse("2006-01-02", value); err == nil {
-		return dateVal, "date"
-	}
-	if dateVal, err := time.Parse("2006-01-02 15:04:05", value); err == nil {
-		return dateVal, "datetime"
-	}
+	// Default to text
+	return value, "text"
+}
 
-	// Try currency
-	if strings.HasPrefix(value, "$") {
+// parseDataType parses a trimmed value as one of the data types of
+// InferDataType. Currencies may omit their symbol.
+func parseDataType(value, dataType string) (interface{}, bool) {
+	switch dataType {
+	case "boolean":
+		switch strings.ToLower(value) {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	case "integer":
+		if intVal, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intVal, true
+		}
+	case "float":
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal, true
+		}
+	case "date", "datetime":
+		if dateVal, err := time.Parse(dataTypeLayouts[dataType], value); err == nil {
+			return dateVal, true
+		}
+	case "currency":
 		currencyStr := strings.ReplaceAll(strings.TrimPrefix(value, "$"), ",", "")
 		if currencyVal, err := strconv.ParseFloat(currencyStr, 64); err == nil {
-			return currencyVal, "currency"
+			return currencyVal, true
 		}
-	}
-
-	// Try percentage
-	if strings.HasSuffix(value, "%") {
-		percentStr := strings.TrimSuffix(value, "%")
-		if percentVal, err := strconv.ParseFloat(percentStr, 64); err == nil {
-			return percentVal / 100, "percentage"
+	case "percentage":
+		if strings.HasSuffix(value, "%") {
+			if percentVal, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64); err == nil {
+				return percentVal / 100, true
+			}
 		}
 	}
-
-	// Default to text
-	return value, "text"
+	return nil, false
 }
 
 // ValidateDataType validates if a value matches the expected data type
@@ -286,52 +302,104 @@ func (dt *DataTransformer) TransformJSONToRows(jsonData map[string]interface{})
 	return rows, nil
 }
 
-// PivotData transforms data into pivot format
+// PivotData transforms data into a crosstab. Rows are grouped by RowFields,
+// ColumnFields values are spread into columns and ValueFields are aggregated
+// with config.Aggregation. The first row is the header; subtotal rows (when
+// there is more than one row field) and a grand total row are included.
 func (dt *DataTransformer) PivotData(data []map[string]interface{}, config PivotConfig) ([][]interface{}, error) {
-	// Simple pivot implementation
-	var result [][]interface{}
-
-	// Add header row
-	header := make([]interface{}, 0)
-	for _, field := range config.RowFields {
-		header = append(header, field)
+	result, err := computePivot(data, config)
+	if err != nil {
+		return nil, err
 	}
-	for _, field := range config.ColumnFields {
-		header = append(header, field)
+	return result.rows, nil
+}
+
+// RecordsToRows converts a slice of maps or structs into a header and rows.
+// The header is columns, selecting and ordering the keys of maps or the
+// exported fields of structs. When columns is empty it is the sorted union
+// of all keys for maps and the exported field names in declaration order for
+// structs.
+func (dt *DataTransformer) RecordsToRows(records interface{}, columns []string) ([]string, [][]interface{}, error) {
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, nil, fmt.Errorf("records must be a slice, got %T", records)
 	}
-	for _, field := range config.ValueFields {
-		header = append(header, field)
+
+	elemType := v.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
 	}
-	result = append(result, header)
-
-	// Add data rows
-	for _, row := range data {
-		dataRow := make([]interface{}, 0)
-		for _, field := range config.RowFields {
-			if value, ok := row[field]; ok {
-				dataRow = append(dataRow, value)
-			} else {
-				dataRow = append(dataRow, "")
+
+	switch elemType.Kind() {
+	case reflect.Map:
+		if elemType.Key().Kind() != reflect.String {
+			return nil, nil, fmt.Errorf("record maps must have string keys, got %s", elemType.Key())
+		}
+		header := columns
+		if len(header) == 0 {
+			seen := make(map[string]bool)
+			for i := 0; i < v.Len(); i++ {
+				record := reflect.Indirect(v.Index(i))
+				if !record.IsValid() {
+					continue
+				}
+				for _, key := range record.MapKeys() {
+					if !seen[key.String()] {
+						seen[key.String()] = true
+						header = append(header, key.String())
+					}
+				}
+			}
+			sort.Strings(header)
+		}
+		rows := make([][]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			record := reflect.Indirect(v.Index(i))
+			row := make([]interface{}, len(header))
+			rows[i] = row
+			if !record.IsValid() {
+				continue
+			}
+			for j, column := range header {
+				if value := record.MapIndex(reflect.ValueOf(column).Convert(elemType.Key())); value.IsValid() {
+					row[j] = value.Interface()
+				}
+			}
+		}
+		return header, rows, nil
+	case reflect.Struct:
+		header := columns
+		var fieldIndexes []int
+		if len(header) == 0 {
+			for i := 0; i < elemType.NumField(); i++ {
+				if field := elemType.Field(i); field.IsExported() {
+					header = append(header, field.Name)
+					fieldIndexes = append(fieldIndexes, i)
+				}
 			}
 		}
-		for _, field := range config.ColumnFields {
-			if value, ok := row[field]; ok {
-				dataRow = append(dataRow, value)
-			} else {
-				dataRow = append(dataRow, "")
+		for _, column := range columns {
+			field, ok := elemType.FieldByName(column)
+			if !ok || !field.IsExported() || len(field.Index) != 1 {
+				return nil, nil, fmt.Errorf("column '%s' is not an exported field of %s", column, elemType)
 			}
+			fieldIndexes = append(fieldIndexes, field.Index[0])
 		}
-		for _, field := range config.ValueFields {
-			if value, ok := row[field]; ok {
-				dataRow = append(dataRow, value)
-			} else {
-				dataRow = append(dataRow, "")
+		rows := make([][]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			record := reflect.Indirect(v.Index(i))
+			row := make([]interface{}, len(fieldIndexes))
+			if record.IsValid() {
+				for j, index := range fieldIndexes {
+					row[j] = record.Field(index).Interface()
+				}
 			}
+			rows[i] = row
 		}
-		result = append(result, dataRow)
+		return header, rows, nil
+	default:
+		return nil, nil, fmt.Errorf("records must be maps or structs, got %s", elemType)
 	}
-
-	return result, nil
 }
 
 // FlattenNestedData flattens nested data structures
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"sort"
 	"strconv"
 )
 
@@ -42,8 +41,15 @@ func (ih *ImportHelper) FromCSV(filename string) *ImportHelper {
 	return ih
 }
 
-// FromCSVWithOptions imports data from a CSV file with custom options
+// FromCSVWithOptions imports data from a CSV file with custom options.
+// The encoding and delimiter are detected unless set, and numbers written
+// in the format of options.Locale are imported as numbers.
 func (ih *ImportHelper) FromCSVWithOptions(filename string, options CSVOptions) *ImportHelper {
+	numbers, err := newCSVNumberFormat(options.Locale)
+	if err != nil {
+		panic(fmt.Sprintf("failed to import CSV file: %v", err))
+	}
+
 	file, err := os.Open(filename)
 	if err != nil {
 		panic(fmt.Sprintf("failed to open CSV file: %v", err))
@@ -53,14 +59,11 @@ func (ih *ImportHelper) FromCSVWithOptions(filename string, options CSVOptions)
 	// Set sheet name for CSV with options
 	ih.sheetName = "CustomCSV"
 
-	reader := csv.NewReader(file)
-	if options.Delimiter != "" {
-		reader.Comma = rune(options.Delimiter[0])
-	}
-	// Note: csv.Reader doesn't have Quote field in standard library
-	if options.Comment != 0 {
-		reader.Comment = options.Comment
+	reader, err := newCSVReader(file, options)
+	if err != nil {
+		panic(fmt.Sprintf("failed to import CSV file: %v", err))
 	}
+	reader.ReuseRecord = false
 
 	records, err := reader.ReadAll()
 	if err != nil {
@@ -72,7 +75,14 @@ func (ih *ImportHelper) FromCSVWithOptions(filename string, options CSVOptions)
 		records = records[options.SkipRows:]
 	}
 
-	ih.data = records
+	rows := make([][]interface{}, len(records))
+	for i, record := range records {
+		rows[i] = make([]interface{}, len(record))
+		for j, value := range record {
+			rows[i][j] = parseCSVNumber(value, numbers)
+		}
+	}
+	ih.data = rows
 	return ih
 }
 
@@ -84,9 +94,11 @@ func (ih *ImportHelper) FromJSON(filename string) *ImportHelper {
 	}
 	defer file.Close()
 
-	var data interface{}
+	// Objects keep the order of their keys in the document
 	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&data); err != nil {
+	decoder.UseNumber()
+	data, err := decodeJSONValue(decoder)
+	if err != nil {
 		panic(fmt.Sprintf("failed to decode JSON: %v", err))
 	}
 
@@ -103,13 +115,10 @@ func (ih *ImportHelper) WithFlattenOptions(options FlattenOptions) *ImportHelper
 	}
 
 	// If data is a wrapper object with an array, extract the array first
-	if wrapper, ok := ih.data.(map[string]interface{}); ok {
-		for _, value := range wrapper {
-			if arr, ok := value.([]interface{}); ok {
-				// Found an array, use it as the main data for flattening
-				ih.data = arr
-				break
-			}
+	if wrapper, ok := jsonObjectOf(ih.data); ok {
+		if arr, ok := wrapperArray(wrapper); ok {
+			// Found an array, use it as the main data for flattening
+			ih.data = arr
 		}
 	}
 
@@ -121,6 +130,11 @@ func (ih *ImportHelper) WithFlattenOptions(options FlattenOptions) *ImportHelper
 
 // ToExcel converts imported data to Excel format
 func (ih *ImportHelper) ToExcel() *WorkbookBuilder {
+	// NDJSON is written to its workbook while it is read
+	if workbook, ok := ih.data.(*WorkbookBuilder); ok {
+		return workbook
+	}
+
 	eb := New()
 	workbook := eb.NewWorkbook()
 	sheet := workbook.AddSheet(ih.sheetName)
@@ -134,172 +148,55 @@ func (ih *ImportHelper) ToExcel() *WorkbookBuilder {
 				excelRow.AddCell(cell)
 			}
 		}
-	case []interface{}:
-		// JSON array
-		if len(data) > 0 {
-			// Create headers from first object
-			if firstObj, ok := data[0].(map[string]interface{}); ok {
-				headerRow := sheet.AddRow()
-				var keys []string
-				for key := range firstObj {
-					keys = append(keys, key)
-				}
-				sort.Strings(keys)
-				for _, key := range keys {
-					headerRow.AddCell(key)
-				}
-
-				// Add data rows
-				for _, item := range data {
-					if obj, ok := item.(map[string]interface{}); ok {
-						dataRow := sheet.AddRow()
-						for _, key := range keys {
-							value := obj[key]
-							dataRow.AddCell(value)
-						}
-					}
-				}
-			}
-		}
-	case map[string]interface{}:
-		// Check if this is a wrapper object with an array inside
-		var arrayFound bool
-		for _, value := range data {
-			if arr, ok := value.([]interface{}); ok {
-				// Found an array, use it as the main data
-				if len(arr) > 0 {
-					if firstObj, ok := arr[0].(map[string]interface{}); ok {
-						headerRow := sheet.AddRow()
-						var keys []string
-						for key := range firstObj {
-							keys = append(keys, key)
-						}
-						sort.Strings(keys)
-						for _, key := range keys {
-							headerRow.AddCell(key)
-						}
-
-						// Add data rows
-						for _, item := range arr {
-							if obj, ok := item.(map[string]interface{}); ok {
-								dataRow := sheet.AddRow()
-								for _, key := range keys {
-									value := obj[key]
-									dataRow.AddCell(value)
-								}
-							}
-						}
-					}
-					arrayFound = true
-					break
-				}
+	case [][]interface{}:
+		// CSV data with converted values
+		for _, row := range data {
+			excelRow := sheet.AddRow()
+			for _, cell := range row {
+				excelRow.AddCell(cell)
 			}
 		}
-		
-		if !arrayFound {
+	case []interface{}:
+		// JSON array, with nested arrays of objects in child sheets
+		writeJSONRecords(workbook, ih.jsonSheetNames(workbook), ih.sheetName, data)
+	case *jsonObject:
+		if arr, ok := wrapperArray(data); ok {
+			// Wrapper object with an array inside
+			writeJSONRecords(workbook, ih.jsonSheetNames(workbook), ih.sheetName, arr)
+		} else {
 			// Single JSON object
-			headerRow := sheet.AddRow()
-			dataRow := sheet.AddRow()
-			for key, value := range data {
-				headerRow.AddCell(key)
-				dataRow.AddCell(value)
-			}
+			writeJSONRecords(workbook, ih.jsonSheetNames(workbook), ih.sheetName, []interface{}{data})
 		}
 	}
 
 	return workbook
 }
 
-// FromExcel sets the Excel file for export
-func (eh *ExportHelper) FromExcel(workbook *WorkbookBuilder) *ExportHelper {
-	eh.file = workbook.Build()
-	return eh
-}
-
-// ToCSV exports Excel data to CSV format
-func (eh *ExportHelper) ToCSV(filename string) *ExportHelper {
-	if eh.file == nil {
-		panic("no Excel file set for export")
-	}
-
-	outFile, err := os.Create(filename)
-	if err != nil {
-		panic(fmt.Sprintf("failed to create CSV file: %v", err))
-	}
-	defer outFile.Close()
-
-	writer := csv.NewWriter(outFile)
-	defer writer.Flush()
-
-	// Get sheet names and find the one with data
-	sheetNames := eh.file.GetSheetList()
-	if len(sheetNames) > 0 {
-		// Try to find a sheet with data, skip default empty sheets
-		var targetSheet string
-		for _, sheetName := range sheetNames {
-			if sheetName != "Sheet1" { // Skip default empty sheet
-				targetSheet = sheetName
-				break
-			}
-		}
-		// If no non-default sheet found, use the first one
-		if targetSheet == "" {
-			targetSheet = sheetNames[0]
-		}
-		
-		rows, err := eh.file.GetRows(targetSheet)
-		if err == nil {
-			for _, row := range rows {
-				writer.Write(row)
-			}
+// jsonSheetNames returns the sheet names of JSON data, whose child sheets
+// must not reuse the other sheets of the workbook
+func (ih *ImportHelper) jsonSheetNames(workbook *WorkbookBuilder) jsonSheetNames {
+	var reserved []string
+	for _, name := range workbook.file.GetSheetList() {
+		if name != ih.sheetName {
+			reserved = append(reserved, name)
 		}
 	}
-
-	return eh
+	return newJSONSheetNames(reserved...)
 }
 
-// ToCSVWithOptions exports Excel data to CSV with custom options
-func (eh *ExportHelper) ToCSVWithOptions(filename string, options CSVOptions) *ExportHelper {
-	if eh.file == nil {
-		panic("no Excel file set for export")
-	}
-
-	outFile, err := os.Create(filename)
-	if err != nil {
-		panic(fmt.Sprintf("failed to create CSV file: %v", err))
-	}
-	defer outFile.Close()
-
-	writer := csv.NewWriter(outFile)
-	if options.Delimiter != "" {
-		writer.Comma = rune(options.Delimiter[0])
-	}
-	defer writer.Flush()
-
-	// Get sheet names and find the best sheet to export
-	sheetNames := eh.file.GetSheetList()
-	var selectedSheet string
-	if len(sheetNames) > 0 {
-		// Prefer sheets other than "Sheet1" (which is often empty)
-		for _, name := range sheetNames {
-			if name != "Sheet1" {
-				selectedSheet = name
-				break
-			}
-		}
-		// If no other sheet found, use the first one
-		if selectedSheet == "" {
-			selectedSheet = sheetNames[0]
-		}
-
-		rows, err := eh.file.GetRows(selectedSheet)
-		if err == nil {
-			for _, row := range rows {
-				writer.Write(row)
-			}
+// wrapperArray returns the first array value of a wrapper object
+func wrapperArray(wrapper *jsonObject) ([]interface{}, bool) {
+	for _, key := range wrapper.keys {
+		if arr, ok := wrapper.values[key].([]interface{}); ok {
+			return arr, true
 		}
 	}
+	return nil, false
+}
 
+// FromExcel sets the Excel file for export
+func (eh *ExportHelper) FromExcel(workbook *WorkbookBuilder) *ExportHelper {
+	eh.file = workbook.Build()
 	return eh
 }
 
@@ -375,7 +272,7 @@ func (ih *ImportHelper) flattenData(data interface{}, options FlattenOptions) in
 			result = append(result, ih.flattenObject(item, "", separator, options.MaxDepth, 0))
 		}
 		return result
-	case map[string]interface{}:
+	case *jsonObject, map[string]interface{}:
 		return ih.flattenObject(v, "", separator, options.MaxDepth, 0)
 	default:
 		return data
@@ -389,6 +286,29 @@ func (ih *ImportHelper) flattenObject(obj interface{}, prefix, separator string,
 	}
 
 	switch v := obj.(type) {
+	case *jsonObject:
+		result := newJSONObject()
+		for _, key := range v.keys {
+			newKey := key
+			if prefix != "" {
+				newKey = prefix + separator + key
+			}
+
+			switch nested := v.values[key].(type) {
+			case *jsonObject, []interface{}:
+				flattened := ih.flattenObject(nested, newKey, separator, maxDepth, currentDepth+1)
+				if flatObj, ok := flattened.(*jsonObject); ok {
+					for _, k := range flatObj.keys {
+						result.set(k, flatObj.values[k])
+					}
+				} else {
+					result.set(newKey, flattened)
+				}
+			default:
+				result.set(newKey, nested)
+			}
+		}
+		return result
 	case map[string]interface{}:
 		result := make(map[string]interface{})
 		for key, value := range v {
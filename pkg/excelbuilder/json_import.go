@@ -0,0 +1,360 @@
+package excelbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// jsonParentIDColumn is the column of child sheets that refers to the row
+// of the parent sheet
+const jsonParentIDColumn = "parent_id"
+
+// jsonObject is a JSON object that keeps the order of its keys
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// newJSONObject creates an empty jsonObject
+func newJSONObject() *jsonObject {
+	return &jsonObject{values: make(map[string]interface{})}
+}
+
+// set sets the value of key, appending key if it is new
+func (o *jsonObject) set(key string, value interface{}) {
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON encodes the object with its keys in order. Like ToNDJSON,
+// it leaves HTML characters unescaped.
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := encoder.Encode(key); err != nil {
+			return nil, err
+		}
+		// Encode ends every value with a line break
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte(':')
+		if err := encoder.Encode(o.values[key]); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonObjectOf returns value as a jsonObject. The keys of a map, which has
+// no order, are sorted.
+func jsonObjectOf(value interface{}) (*jsonObject, bool) {
+	switch v := value.(type) {
+	case *jsonObject:
+		return v, true
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		obj := newJSONObject()
+		for _, key := range keys {
+			obj.set(key, v[key])
+		}
+		return obj, true
+	default:
+		return nil, false
+	}
+}
+
+// decodeJSONValue decodes the next JSON value of dec, which must have
+// UseNumber set. Objects are decoded as *jsonObject, arrays as
+// []interface{} and numbers as int64 when they are integers that fit, so
+// that large IDs keep every digit, or float64 otherwise.
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		obj := newJSONObject()
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key.(string), value)
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		items := []interface{}{}
+		for dec.More() {
+			item, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := dec.Token()
+		return items, err
+	default:
+		if number, ok := token.(json.Number); ok {
+			return jsonNumberValue(number)
+		}
+		return token, nil
+	}
+}
+
+// jsonNumberValue converts a JSON number to int64, or to float64 when it
+// is not an integer or does not fit
+func jsonNumberValue(number json.Number) (interface{}, error) {
+	if integer, err := number.Int64(); err == nil {
+		return integer, nil
+	}
+	float, err := number.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON number %s: %w", number, err)
+	}
+	return float, nil
+}
+
+// jsonSheetNames hands out the names of the sheets written from JSON data
+type jsonSheetNames map[string]bool
+
+// newJSONSheetNames creates the sheet names of JSON data, which differ
+// from the reserved names of other sheets
+func newJSONSheetNames(reserved ...string) jsonSheetNames {
+	names := make(jsonSheetNames)
+	for _, name := range reserved {
+		names[strings.ToLower(name)] = true
+	}
+	return names
+}
+
+// unique returns name made into a valid sheet name that is not used yet.
+// Excel compares sheet names case-insensitively.
+func (names jsonSheetNames) unique(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Data"
+	}
+
+	unique := truncateSheetName(name, "")
+	for i := 2; names[strings.ToLower(unique)]; i++ {
+		unique = truncateSheetName(name, fmt.Sprintf(" (%d)", i))
+	}
+	names[strings.ToLower(unique)] = true
+	return unique
+}
+
+// truncateSheetName shortens name so that name followed by suffix fits the
+// 31 character limit of sheet names
+func truncateSheetName(name, suffix string) string {
+	for len(name)+len(suffix) > 31 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name + suffix
+}
+
+// jsonSheetWriter writes JSON objects to the rows of a sheet one at a
+// time, below a header row with a column per key path. Keys that were not
+// seen before add a header cell, and nested arrays of objects are written
+// to child sheets whose parent_id column refers to the row of their parent.
+// A parent_id field of the child objects themselves is written to a column
+// named after their array, such as lines.parent_id.
+type jsonSheetWriter struct {
+	workbook *WorkbookBuilder
+	names    jsonSheetNames
+	key      string // Key path of the array of a child sheet
+	name     string
+	sheet    *SheetBuilder
+	child    bool
+	columns  []string
+	header   int // Columns with a header cell
+	children map[string]*jsonSheetWriter
+}
+
+// newJSONSheetWriter adds a sheet named after name and returns its writer
+func newJSONSheetWriter(workbook *WorkbookBuilder, names jsonSheetNames, name string, child bool) *jsonSheetWriter {
+	w := &jsonSheetWriter{
+		workbook: workbook,
+		names:    names,
+		key:      name,
+		name:     names.unique(name),
+		child:    child,
+		children: make(map[string]*jsonSheetWriter),
+	}
+	if child {
+		w.columns = append(w.columns, jsonParentIDColumn)
+	}
+	w.sheet = workbook.AddSheet(w.name)
+	w.sheet.AddRow()
+	w.writeHeader()
+	return w
+}
+
+// writeJSONRecords writes the objects of records to a sheet named after
+// name. Items that are not objects are skipped.
+func writeJSONRecords(workbook *WorkbookBuilder, names jsonSheetNames, name string, records []interface{}) {
+	w := newJSONSheetWriter(workbook, names, name, false)
+	for _, record := range records {
+		if obj, ok := jsonObjectOf(record); ok {
+			w.write(obj, nil)
+		}
+	}
+}
+
+// write writes obj to the next row. parentID is the ID of the parent row
+// of a child sheet.
+func (w *jsonSheetWriter) write(obj *jsonObject, parentID interface{}) {
+	row := make(map[string]interface{})
+	type nestedArray struct {
+		key   string
+		items []interface{}
+	}
+	var nested []nestedArray
+	w.flatten(obj, "", row, func(key string, items []interface{}) {
+		nested = append(nested, nestedArray{key, items})
+	})
+	if w.child {
+		row[jsonParentIDColumn] = parentID
+	}
+	w.writeHeader()
+
+	// The header row makes the current row the number of the new data row
+	id := jsonRowID(obj, w.sheet.GetCurrentRow())
+	values := make([]interface{}, len(w.columns))
+	for i, column := range w.columns {
+		values[i] = row[column]
+	}
+	w.sheet.AddRow().AddCells(values...)
+
+	for _, array := range nested {
+		child, ok := w.children[array.key]
+		if !ok {
+			child = newJSONSheetWriter(w.workbook, w.names, array.key, true)
+			w.children[array.key] = child
+		}
+		for _, item := range array.items {
+			itemObj, _ := jsonObjectOf(item)
+			child.write(itemObj, id)
+		}
+	}
+}
+
+// flatten adds the values of obj to row under their dotted key path.
+// Arrays of objects are passed to child instead.
+func (w *jsonSheetWriter) flatten(obj *jsonObject, prefix string, row map[string]interface{}, child func(key string, items []interface{})) {
+	for _, key := range obj.keys {
+		path := prefix + key
+		if w.child && path == jsonParentIDColumn {
+			path = w.key + "." + path
+		}
+		switch value := obj.values[key].(type) {
+		case *jsonObject, map[string]interface{}:
+			nested, _ := jsonObjectOf(value)
+			w.flatten(nested, path+".", row, child)
+		case []interface{}:
+			if len(value) == 0 {
+				continue
+			}
+			if isJSONObjectArray(value) {
+				child(path, value)
+				continue
+			}
+			w.addColumn(path)
+			row[path] = jsonArrayText(value)
+		default:
+			w.addColumn(path)
+			row[path] = value
+		}
+	}
+}
+
+// addColumn appends column unless the sheet already has it
+func (w *jsonSheetWriter) addColumn(column string) {
+	if !containsString(w.columns, column) {
+		w.columns = append(w.columns, column)
+	}
+}
+
+// writeHeader writes the header cells of the columns added since the last
+// call
+func (w *jsonSheetWriter) writeHeader() {
+	for ; w.header < len(w.columns); w.header++ {
+		cell, _ := excelize.CoordinatesToCellName(w.header+1, 1)
+		if err := w.workbook.file.SetCellValue(w.name, cell, w.columns[w.header]); err != nil {
+			w.workbook.excelBuilder.AddError(fmt.Errorf("failed to write header of sheet '%s': %w", w.name, err))
+		}
+	}
+}
+
+// jsonRowID returns the value of the id field of obj, or the row number
+// when it has none
+func jsonRowID(obj *jsonObject, row int) interface{} {
+	for _, key := range obj.keys {
+		if strings.EqualFold(key, "id") {
+			switch obj.values[key].(type) {
+			case string, int64, float64, bool:
+				return obj.values[key]
+			}
+		}
+	}
+	return row
+}
+
+// isJSONObjectArray reports whether every item of items is an object
+func isJSONObjectArray(items []interface{}) bool {
+	for _, item := range items {
+		if _, ok := jsonObjectOf(item); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonArrayText returns the text of an array written to a single cell:
+// its scalar items separated by commas, or its JSON encoding
+func jsonArrayText(items []interface{}) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		switch item.(type) {
+		case *jsonObject, map[string]interface{}, []interface{}:
+			encoded, _ := json.Marshal(items)
+			return string(encoded)
+		case nil:
+			parts[i] = ""
+		default:
+			parts[i] = fmt.Sprint(item)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,194 @@
+package excelbuilder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// FromNDJSON imports newline-delimited JSON with an object per line, as
+// described in WorkbookBuilder.AddSheetFromNDJSON. A malformed line is
+// returned as an error.
+func (ih *ImportHelper) FromNDJSON(r io.Reader) (*ImportHelper, error) {
+	// Set sheet name for NDJSON imports
+	ih.sheetName = "NDJSONData"
+
+	workbook := New().NewWorkbook()
+	if err := workbook.AddSheetFromNDJSON(ih.sheetName, r); err != nil {
+		workbook.Build().Close()
+		return nil, fmt.Errorf("failed to import NDJSON: %w", err)
+	}
+
+	ih.data = workbook
+	return ih, nil
+}
+
+// AddSheetFromNDJSON imports newline-delimited JSON with an object per line
+// into a new sheet. Every record is written to a row as soon as its line is
+// read, so the input is never held in memory: keys add header columns when
+// they first appear and nested arrays of objects are written to child
+// sheets with a parent_id column. Blank lines are skipped. A malformed line
+// stops the import; the records before it stay in the sheet.
+func (wb *WorkbookBuilder) AddSheetFromNDJSON(name string, r io.Reader) error {
+	if err := validateSheetName(name); err != nil {
+		return err
+	}
+	if index, _ := wb.file.GetSheetIndex(name); index != -1 {
+		return fmt.Errorf("sheet '%s' already exists", name)
+	}
+	writer := newJSONSheetWriter(wb, newJSONSheetNames(wb.file.GetSheetList()...), name, false)
+
+	reader := bufio.NewReader(r)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read NDJSON: %w", err)
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			record, decodeErr := decodeJSONRecord(line)
+			if decodeErr != nil {
+				return fmt.Errorf("invalid NDJSON on line %d: %w", lineNumber, decodeErr)
+			}
+			writer.write(record, nil)
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// ToNDJSON writes a sheet as newline-delimited JSON, one object per row
+// below the header row. Each row is written as soon as it is read, but the
+// type and style of the cells are looked up in the worksheet, which
+// excelize loads into memory as a whole. Keys are the header cells, or the
+// column name for columns without one. Numbers and booleans are written as
+// JSON numbers and booleans, dates and other values as their displayed
+// text, and empty cells are left out.
+func (eh *ExportHelper) ToNDJSON(w io.Writer, sheetName string) error {
+	if eh.file == nil {
+		return fmt.Errorf("no Excel file set for export")
+	}
+	index, err := eh.file.GetSheetIndex(sheetName)
+	if err != nil {
+		return err
+	}
+	if index == -1 {
+		return fmt.Errorf("sheet '%s' does not exist", sheetName)
+	}
+
+	rows, err := eh.file.Rows(sheetName)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	dateStyles := make(map[int]bool)
+	var headers []string
+	for rowNumber := 1; rows.Next(); rowNumber++ {
+		values, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+		if rowNumber == 1 {
+			headers = values
+			continue
+		}
+
+		record := newJSONObject()
+		for col, value := range values {
+			if value == "" {
+				continue
+			}
+			key := ""
+			if col < len(headers) {
+				key = headers[col]
+			}
+			if key == "" {
+				key, _ = excelize.ColumnNumberToName(col + 1)
+			}
+			cell, _ := excelize.CoordinatesToCellName(col+1, rowNumber)
+			typed, err := eh.typedCellValue(sheetName, cell, value, dateStyles)
+			if err != nil {
+				return err
+			}
+			record.set(key, typed)
+		}
+		if len(record.keys) == 0 {
+			continue
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return rows.Error()
+}
+
+// typedCellValue returns the value of a cell for JSON: a bool for boolean
+// cells, an int64 or float64 for numbers without a date format and the
+// displayed text otherwise. dateStyles caches whether a style shows dates.
+func (eh *ExportHelper) typedCellValue(sheetName, cell, displayed string, dateStyles map[int]bool) (interface{}, error) {
+	cellType, err := eh.file.GetCellType(sheetName, cell)
+	if err != nil {
+		return nil, err
+	}
+	switch cellType {
+	case excelize.CellTypeBool:
+		raw, err := eh.file.GetCellValue(sheetName, cell, excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, err
+		}
+		return raw == "1", nil
+	case excelize.CellTypeNumber, excelize.CellTypeUnset:
+		styleID, err := eh.file.GetCellStyle(sheetName, cell)
+		if err != nil {
+			return nil, err
+		}
+		isDate, ok := dateStyles[styleID]
+		if !ok {
+			style, err := eh.file.GetStyle(styleID)
+			isDate = err == nil && isDateNumFmt(style)
+			dateStyles[styleID] = isDate
+		}
+		if isDate {
+			return displayed, nil
+		}
+		raw, err := eh.file.GetCellValue(sheetName, cell, excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, err
+		}
+		if integer, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return integer, nil
+		}
+		if float, err := strconv.ParseFloat(raw, 64); err == nil {
+			return float, nil
+		}
+	}
+	return displayed, nil
+}
+
+// decodeJSONRecord decodes a line holding a single JSON object
+func decodeJSONRecord(line []byte) (*jsonObject, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	value, err := decodeJSONValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unexpected data after the record")
+	}
+	record, ok := value.(*jsonObject)
+	if !ok {
+		return nil, fmt.Errorf("record is not an object")
+	}
+	return record, nil
+}
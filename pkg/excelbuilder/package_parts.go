@@ -0,0 +1,391 @@
+package excelbuilder
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Relationship types of the package parts patched after excelize wrote them
+const (
+	relTypeOfficeDocument = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+	relTypePivotTable     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/pivotTable"
+	relTypePivotCache     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/pivotCacheDefinition"
+	relTypeSlicerCache    = "http://schemas.microsoft.com/office/2007/relationships/slicerCache"
+	relTypeDrawing        = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/drawing"
+	relTypeChart          = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/chart"
+)
+
+// xmlNode is an element of a package part. Element and attribute names keep
+// their namespace prefix, so a part is written back the way it was read.
+// The document itself is a node without a name.
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	children []interface{} // *xmlNode, xml.CharData, xml.Comment, xml.ProcInst or xml.Directive
+}
+
+// parseXMLPart parses the content of a package part
+func parseXMLPart(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	stack := []*xmlNode{{}}
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: prefixedName(t.Name)}
+			for _, attr := range t.Attr {
+				node.attrs = append(node.attrs, xml.Attr{Name: xml.Name{Local: prefixedName(attr.Name)}, Value: attr.Value})
+			}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) == 1 || parent.name != prefixedName(t.Name) {
+				return nil, fmt.Errorf("unexpected end element %s", prefixedName(t.Name))
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			parent.children = append(parent.children, xml.CopyToken(token))
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("element %s is not closed", stack[len(stack)-1].name)
+	}
+	return stack[0], nil
+}
+
+// prefixedName returns a raw XML name as written, with its prefix
+func prefixedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// bytes encodes the node and its children
+func (n *xmlNode) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := xml.NewEncoder(&buf)
+	if err := n.encode(encoder); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encode writes the node to encoder. Names go out as the local part, which
+// makes the encoder write the prefix as it is instead of binding its own.
+func (n *xmlNode) encode(encoder *xml.Encoder) error {
+	if n.name != "" {
+		if err := encoder.EncodeToken(xml.StartElement{Name: xml.Name{Local: n.name}, Attr: n.attrs}); err != nil {
+			return err
+		}
+	}
+	for _, child := range n.children {
+		var err error
+		if node, ok := child.(*xmlNode); ok {
+			err = node.encode(encoder)
+		} else {
+			err = encoder.EncodeToken(child)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if n.name != "" {
+		return encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: n.name}})
+	}
+	return nil
+}
+
+// root returns the root element of a document
+func (n *xmlNode) root() *xmlNode {
+	for _, child := range n.children {
+		if node, ok := child.(*xmlNode); ok {
+			return node
+		}
+	}
+	return nil
+}
+
+// elements returns the child elements with the given local name
+func (n *xmlNode) elements(name string) []*xmlNode {
+	if n == nil {
+		return nil
+	}
+	var nodes []*xmlNode
+	for _, child := range n.children {
+		if node, ok := child.(*xmlNode); ok && localName(node.name) == name {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// element returns the first child element with the given local name, or nil
+func (n *xmlNode) element(name string) *xmlNode {
+	if nodes := n.elements(name); len(nodes) > 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+// attr returns the value of an attribute, or an empty string
+func (n *xmlNode) attr(name string) string {
+	if n == nil {
+		return ""
+	}
+	for _, attr := range n.attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// setAttr sets the value of an attribute, appending it if it is missing
+func (n *xmlNode) setAttr(name, value string) {
+	for i, attr := range n.attrs {
+		if attr.Name.Local == name {
+			n.attrs[i].Value = value
+			return
+		}
+	}
+	n.attrs = append(n.attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// removeAttr removes an attribute
+func (n *xmlNode) removeAttr(name string) {
+	for i, attr := range n.attrs {
+		if attr.Name.Local == name {
+			n.attrs = append(n.attrs[:i], n.attrs[i+1:]...)
+			return
+		}
+	}
+}
+
+// text returns the character data of the node
+func (n *xmlNode) text() string {
+	var text strings.Builder
+	for _, child := range n.children {
+		if data, ok := child.(xml.CharData); ok {
+			text.Write(data)
+		}
+	}
+	return text.String()
+}
+
+// setText replaces the children of the node with text
+func (n *xmlNode) setText(text string) {
+	n.children = []interface{}{xml.CharData(text)}
+}
+
+// walk calls visit for the node and every element below it
+func (n *xmlNode) walk(visit func(node *xmlNode)) {
+	visit(n)
+	for _, child := range n.children {
+		if node, ok := child.(*xmlNode); ok {
+			node.walk(visit)
+		}
+	}
+}
+
+// replace replaces the child element old with node
+func (n *xmlNode) replace(old, node *xmlNode) {
+	for i, child := range n.children {
+		if child == old {
+			n.children[i] = node
+			return
+		}
+	}
+}
+
+// remove removes the child element node
+func (n *xmlNode) remove(node *xmlNode) {
+	for i, child := range n.children {
+		if child == node {
+			n.children = append(n.children[:i], n.children[i+1:]...)
+			return
+		}
+	}
+}
+
+// localName returns a prefixed name without its prefix
+func localName(name string) string {
+	return name[strings.IndexByte(name, ':')+1:]
+}
+
+// loadXMLPart parses a package part
+func loadXMLPart(file *excelize.File, partPath string) (*xmlNode, error) {
+	data, ok := loadPart(file, partPath)
+	if !ok {
+		return nil, fmt.Errorf("part %s not found", partPath)
+	}
+	doc, err := parseXMLPart(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", partPath, err)
+	}
+	return doc, nil
+}
+
+// storeXMLPart writes a parsed package part back to the package
+func storeXMLPart(file *excelize.File, partPath string, doc *xmlNode) error {
+	data, err := doc.bytes()
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", partPath, err)
+	}
+	file.Pkg.Store(partPath, data)
+	return nil
+}
+
+// packageRelationship is a relationship of a package part
+type packageRelationship struct {
+	ID         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr"`
+}
+
+// partRelationships returns the relationships of a package part, or of the
+// package itself when partPath is empty
+func partRelationships(file *excelize.File, partPath string) ([]packageRelationship, error) {
+	relsPath := "_rels/.rels"
+	if partPath != "" {
+		relsPath = path.Join(path.Dir(partPath), "_rels", path.Base(partPath)+".rels")
+	}
+
+	var data []byte
+	if cached, ok := file.Relationships.Load(relsPath); ok && cached != nil {
+		// excelize keeps the relationships it changes in memory until the
+		// workbook is written
+		encoded, err := xml.Marshal(cached)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", relsPath, err)
+		}
+		data = encoded
+	} else if data, ok = loadPart(file, relsPath); !ok {
+		return nil, nil
+	}
+
+	var rels struct {
+		Relationships []packageRelationship `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", relsPath, err)
+	}
+	return rels.Relationships, nil
+}
+
+// relatedParts returns the paths of the parts partPath refers to with
+// relationships of the given type
+func relatedParts(file *excelize.File, partPath, relType string) ([]string, error) {
+	rels, err := partRelationships(file, partPath)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, rel := range rels {
+		if rel.Type == relType && rel.TargetMode != "External" {
+			paths = append(paths, resolvePartTarget(partPath, rel.Target))
+		}
+	}
+	return paths, nil
+}
+
+// resolvePartTarget returns the path of a relationship target, which is
+// relative to the part unless it starts with a slash
+func resolvePartTarget(partPath, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(path.Dir(partPath), target)
+}
+
+// workbookPart returns the path of the workbook part
+func workbookPart(file *excelize.File) (string, error) {
+	workbooks, err := relatedParts(file, "", relTypeOfficeDocument)
+	if err != nil {
+		return "", err
+	}
+	if len(workbooks) == 0 {
+		return "", fmt.Errorf("workbook part not found")
+	}
+	return workbooks[0], nil
+}
+
+// sheetPart returns the path of the worksheet part of a sheet and its sheet ID
+func sheetPart(file *excelize.File, sheet string) (string, int, error) {
+	workbook, err := workbookPart(file)
+	if err != nil {
+		return "", 0, err
+	}
+	rels, err := partRelationships(file, workbook)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// GetSheetList loads the workbook part if excelize has not read it yet
+	file.GetSheetList()
+	for _, s := range file.WorkBook.Sheets.Sheet {
+		if s.Name != sheet {
+			continue
+		}
+		for _, rel := range rels {
+			if rel.ID == s.ID {
+				return resolvePartTarget(workbook, rel.Target), s.SheetID, nil
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("sheet '%s' not found", sheet)
+}
+
+// pivotTableParts returns the paths of the part of the pivot table named
+// name on sheet and of its cache definition. If several pivot tables on the
+// sheet share the name, the one added last is used.
+func pivotTableParts(file *excelize.File, sheet, name string) (table, cache string, err error) {
+	sheetPath, _, err := sheetPart(file, sheet)
+	if err != nil {
+		return "", "", err
+	}
+	tables, err := relatedParts(file, sheetPath, relTypePivotTable)
+	if err != nil {
+		return "", "", err
+	}
+	for _, candidate := range tables {
+		doc, err := loadXMLPart(file, candidate)
+		if err != nil {
+			return "", "", err
+		}
+		if doc.root().attr("name") == name {
+			table = candidate
+		}
+	}
+	if table == "" {
+		return "", "", fmt.Errorf("pivot table '%s' not found on sheet '%s'", name, sheet)
+	}
+
+	caches, err := relatedParts(file, table, relTypePivotCache)
+	if err != nil {
+		return "", "", err
+	}
+	if len(caches) == 0 {
+		return "", "", fmt.Errorf("cache definition of pivot table '%s' not found", name)
+	}
+	return table, caches[0], nil
+}
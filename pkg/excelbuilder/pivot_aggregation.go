@@ -0,0 +1,432 @@
+package excelbuilder
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of rows produced by an in-memory pivot
+const (
+	pivotRowHeader = iota
+	pivotRowData
+	pivotRowSubtotal
+	pivotRowGrandTotal
+)
+
+// pivotResult holds a computed crosstab together with the kind of each row,
+// so callers can style headers and totals differently.
+type pivotResult struct {
+	rows  [][]interface{}
+	kinds []int
+}
+
+// pivotKey is a unique combination of field values
+type pivotKey struct {
+	id     string
+	values []interface{}
+}
+
+// pivotAggregator accumulates raw values per (row key, column key, value field)
+type pivotAggregator struct {
+	config  PivotConfig
+	buckets map[string][][]interface{}
+}
+
+// Bucket key prefixes for detail rows, subtotal rows and the grand total row.
+// The column part is empty for the row total column.
+const (
+	pivotDetailPrefix   = "r:"
+	pivotSubtotalPrefix = "s:"
+	pivotGrandTotalKey  = "g"
+)
+
+// computePivot groups data by the row fields, spreads the column field values
+// into columns and aggregates the value fields.
+func computePivot(data []map[string]interface{}, config PivotConfig) (*pivotResult, error) {
+	if len(config.ValueFields) == 0 {
+		return nil, fmt.Errorf("pivot requires at least one value field")
+	}
+	aggregation := pivotAggregationName(config)
+	if aggregation == "custom" && config.CustomAggregation == nil {
+		return nil, fmt.Errorf("custom pivot aggregation requires CustomAggregation to be set")
+	}
+	if _, err := aggregatePivotValues(config, nil); err != nil {
+		return nil, err
+	}
+
+	agg := &pivotAggregator{
+		config:  config,
+		buckets: make(map[string][][]interface{}),
+	}
+
+	rowKeys := make(map[string]pivotKey)
+	colKeys := make(map[string]pivotKey)
+	for _, record := range data {
+		rowKey := newPivotKey(record, config.RowFields)
+		colKey := newPivotKey(record, config.ColumnFields)
+		rowKeys[rowKey.id] = rowKey
+		colKeys[colKey.id] = colKey
+
+		values := make([]interface{}, len(config.ValueFields))
+		for i, field := range config.ValueFields {
+			values[i] = record[field]
+		}
+
+		agg.add(pivotDetailPrefix+rowKey.id, colKey.id, values)
+		if len(config.RowFields) > 1 {
+			groupID := newPivotKey(record, config.RowFields[:1]).id
+			agg.add(pivotSubtotalPrefix+groupID, colKey.id, values)
+		}
+		agg.add(pivotGrandTotalKey, colKey.id, values)
+	}
+
+	sortedRows := sortPivotKeys(rowKeys)
+	sortedCols := sortPivotKeys(colKeys)
+
+	result := &pivotResult{}
+	result.append(pivotRowHeader, agg.header(sortedCols))
+
+	if len(config.RowFields) == 0 {
+		// Without row fields the grand total is the only row
+		sortedRows = nil
+	}
+
+	var currentGroup *pivotKey
+	for _, rowKey := range sortedRows {
+		if len(config.RowFields) > 1 {
+			group := pivotKey{id: pivotKeyID(rowKey.values[:1]), values: rowKey.values[:1]}
+			if currentGroup != nil && currentGroup.id != group.id {
+				row, err := agg.subtotalRow(*currentGroup, sortedCols)
+				if err != nil {
+					return nil, err
+				}
+				result.append(pivotRowSubtotal, row)
+			}
+			currentGroup = &group
+		}
+
+		row := make([]interface{}, 0, len(rowKey.values))
+		row = append(row, rowKey.values...)
+		cells, err := agg.cells(pivotDetailPrefix+rowKey.id, sortedCols)
+		if err != nil {
+			return nil, err
+		}
+		result.append(pivotRowData, append(row, cells...))
+	}
+	if currentGroup != nil {
+		row, err := agg.subtotalRow(*currentGroup, sortedCols)
+		if err != nil {
+			return nil, err
+		}
+		result.append(pivotRowSubtotal, row)
+	}
+
+	grandTotal := make([]interface{}, max(len(config.RowFields), 1))
+	grandTotal[0] = "Grand Total"
+	cells, err := agg.cells(pivotGrandTotalKey, sortedCols)
+	if err != nil {
+		return nil, err
+	}
+	result.append(pivotRowGrandTotal, append(grandTotal, cells...))
+
+	return result, nil
+}
+
+// append adds a row of the given kind to the result
+func (pr *pivotResult) append(kind int, row []interface{}) {
+	pr.rows = append(pr.rows, row)
+	pr.kinds = append(pr.kinds, kind)
+}
+
+// add records the values of one source record in the bucket of its column
+// and in the row total bucket
+func (agg *pivotAggregator) add(rowID, colID string, values []interface{}) {
+	if len(agg.config.ColumnFields) > 0 {
+		agg.addToBucket(rowID, colID, values)
+	}
+	agg.addToBucket(rowID, "", values)
+}
+
+// addToBucket appends the non-nil values to a single bucket
+func (agg *pivotAggregator) addToBucket(rowID, colID string, values []interface{}) {
+	key := rowID + "\x01" + colID
+	bucket, ok := agg.buckets[key]
+	if !ok {
+		bucket = make([][]interface{}, len(values))
+	}
+	for i, value := range values {
+		if value != nil {
+			bucket[i] = append(bucket[i], value)
+		}
+	}
+	agg.buckets[key] = bucket
+}
+
+// header builds the header row of the crosstab
+func (agg *pivotAggregator) header(colKeys []pivotKey) []interface{} {
+	header := make([]interface{}, 0)
+	for _, field := range agg.config.RowFields {
+		header = append(header, field)
+	}
+	if len(agg.config.RowFields) == 0 {
+		header = append(header, "")
+	}
+
+	if len(agg.config.ColumnFields) > 0 {
+		for _, colKey := range colKeys {
+			for _, field := range agg.config.ValueFields {
+				header = append(header, agg.columnLabel(pivotKeyLabel(colKey.values), field))
+			}
+		}
+	}
+	for _, field := range agg.config.ValueFields {
+		if len(agg.config.ColumnFields) > 0 {
+			header = append(header, agg.columnLabel("Grand Total", field))
+		} else {
+			header = append(header, agg.valueLabel(field))
+		}
+	}
+	return header
+}
+
+// columnLabel returns the header of a spread column
+func (agg *pivotAggregator) columnLabel(prefix, field string) string {
+	if len(agg.config.ValueFields) == 1 {
+		return prefix
+	}
+	return fmt.Sprintf("%s - %s", prefix, agg.valueLabel(field))
+}
+
+// valueLabel returns the display name of an aggregated value field
+func (agg *pivotAggregator) valueLabel(field string) string {
+	return fmt.Sprintf("%s of %s", pivotAggregationName(agg.config), field)
+}
+
+// cells aggregates the buckets of a row for every column key and the row total
+func (agg *pivotAggregator) cells(rowID string, colKeys []pivotKey) ([]interface{}, error) {
+	var cells []interface{}
+	if len(agg.config.ColumnFields) > 0 {
+		for _, colKey := range colKeys {
+			values, err := agg.aggregate(rowID, colKey.id)
+			if err != nil {
+				return nil, err
+			}
+			cells = append(cells, values...)
+		}
+	}
+	values, err := agg.aggregate(rowID, "")
+	if err != nil {
+		return nil, err
+	}
+	return append(cells, values...), nil
+}
+
+// subtotalRow builds the subtotal row closing a group of the first row field
+func (agg *pivotAggregator) subtotalRow(group pivotKey, colKeys []pivotKey) ([]interface{}, error) {
+	row := make([]interface{}, len(agg.config.RowFields))
+	row[0] = fmt.Sprintf("%v Total", group.values[0])
+	cells, err := agg.cells(pivotSubtotalPrefix+group.id, colKeys)
+	if err != nil {
+		return nil, err
+	}
+	return append(row, cells...), nil
+}
+
+// aggregate computes one aggregated value per value field for a bucket
+func (agg *pivotAggregator) aggregate(rowID, colID string) ([]interface{}, error) {
+	bucket, ok := agg.buckets[rowID+"\x01"+colID]
+	result := make([]interface{}, len(agg.config.ValueFields))
+	if !ok {
+		return result, nil
+	}
+	for i, values := range bucket {
+		value, err := aggregatePivotValues(agg.config, values)
+		if err != nil {
+			return nil, fmt.Errorf("value field '%s': %w", agg.config.ValueFields[i], err)
+		}
+		result[i] = value
+	}
+	return result, nil
+}
+
+// pivotAggregationName returns the normalized aggregation of a pivot config
+func pivotAggregationName(config PivotConfig) string {
+	aggregation := strings.ToLower(strings.TrimSpace(config.Aggregation))
+	switch aggregation {
+	case "":
+		if config.CustomAggregation != nil {
+			return "custom"
+		}
+		return "sum"
+	case "average", "mean":
+		return "avg"
+	case "distinct", "distinctcount", "distinct_count", "countdistinct":
+		return "distinct"
+	default:
+		return aggregation
+	}
+}
+
+// aggregatePivotValues applies the configured aggregation to a list of values.
+// Calling it with no values only validates the aggregation name.
+func aggregatePivotValues(config PivotConfig, values []interface{}) (interface{}, error) {
+	aggregation := pivotAggregationName(config)
+	switch aggregation {
+	case "count":
+		return len(values), nil
+	case "distinct":
+		seen := make(map[string]bool)
+		for _, value := range values {
+			seen[fmt.Sprintf("%v", value)] = true
+		}
+		return len(seen), nil
+	case "custom":
+		if values == nil {
+			return nil, nil
+		}
+		return config.CustomAggregation(values), nil
+	case "sum", "avg", "min", "max":
+	default:
+		return nil, fmt.Errorf("unsupported pivot aggregation '%s'", config.Aggregation)
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+	numbers := make([]float64, len(values))
+	for i, value := range values {
+		number, ok := toFloat64(value)
+		if !ok {
+			return nil, fmt.Errorf("cannot %s non-numeric value %v", aggregation, value)
+		}
+		numbers[i] = number
+	}
+
+	switch aggregation {
+	case "sum":
+		return sumFloat64(numbers), nil
+	case "avg":
+		return sumFloat64(numbers) / float64(len(numbers)), nil
+	case "min":
+		result := math.Inf(1)
+		for _, n := range numbers {
+			result = math.Min(result, n)
+		}
+		return result, nil
+	default:
+		result := math.Inf(-1)
+		for _, n := range numbers {
+			result = math.Max(result, n)
+		}
+		return result, nil
+	}
+}
+
+// sumFloat64 returns the sum of numbers
+func sumFloat64(numbers []float64) float64 {
+	var sum float64
+	for _, n := range numbers {
+		sum += n
+	}
+	return sum
+}
+
+// toFloat64 converts numeric Go values and numeric strings to float64
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// newPivotKey builds the key of a record for the given fields
+func newPivotKey(record map[string]interface{}, fields []string) pivotKey {
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		values[i] = record[field]
+	}
+	return pivotKey{id: pivotKeyID(values), values: values}
+}
+
+// pivotKeyID returns a string uniquely identifying a combination of values
+func pivotKeyID(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprintf("%v", value)
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// pivotKeyLabel returns the display label of a combination of values
+func pivotKeyLabel(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprintf("%v", value)
+	}
+	return strings.Join(parts, " / ")
+}
+
+// sortPivotKeys returns the keys ordered field by field
+func sortPivotKeys(keys map[string]pivotKey) []pivotKey {
+	sorted := make([]pivotKey, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		for k := range sorted[i].values {
+			if c := compareValues(sorted[i].values[k], sorted[j].values[k]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return sorted
+}
+
+// compareValues compares two values numerically when both are numbers,
+// and by their string representation otherwise.
+func compareValues(a, b interface{}) int {
+	fa, okA := toFloat64(a)
+	fb, okB := toFloat64(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
@@ -0,0 +1,560 @@
+package excelbuilder
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Kinds of derived pivot fields
+const (
+	derivedDateGroup   = "date"
+	derivedNumberGroup = "number"
+	derivedCalculated  = "calculated"
+)
+
+// PivotDerivedField defines a column computed from the source data before
+// the pivot table is built
+type PivotDerivedField struct {
+	Name         string
+	Type         string // "date", "number" or "calculated"
+	SourceField  string
+	DateGrouping string // "year", "quarter" or "month"
+	Start        float64
+	Interval     float64
+	Expression   string
+}
+
+// dateLayouts lists the text date formats recognized when grouping dates
+// and by the format template filter
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"01/02/2006",
+	"2006/01/02",
+}
+
+// AddDateGroup adds a field named name that groups the dates of sourceField
+// by "year" (2024), "quarter" ("2024-Q1") or "month" ("2024-01").
+// Use name as a row, column or filter field.
+func (ptb *PivotTableBuilder) AddDateGroup(name, sourceField, grouping string) *PivotTableBuilder {
+	ptb.config.DerivedFields = append(ptb.config.DerivedFields, PivotDerivedField{
+		Name:         name,
+		Type:         derivedDateGroup,
+		SourceField:  sourceField,
+		DateGrouping: grouping,
+	})
+	return ptb
+}
+
+// AddNumberGroup adds a field named name that groups the numbers of
+// sourceField into bins of the given interval starting at start, labelled
+// "[lower, upper)" with an inclusive lower and exclusive upper bound. As a
+// row or column field its items are listed in numeric order.
+func (ptb *PivotTableBuilder) AddNumberGroup(name, sourceField string, start, interval float64) *PivotTableBuilder {
+	ptb.config.DerivedFields = append(ptb.config.DerivedFields, PivotDerivedField{
+		Name:        name,
+		Type:        derivedNumberGroup,
+		SourceField: sourceField,
+		Start:       start,
+		Interval:    interval,
+	})
+	return ptb
+}
+
+// AddCalculatedField adds a field named name computed from an arithmetic
+// expression over other fields, e.g. "Profit / Revenue". Field names with
+// spaces must be enclosed in brackets: "[Unit Price] * Quantity".
+// Rows where an operand is not a number or a division by zero occurs are left blank.
+func (ptb *PivotTableBuilder) AddCalculatedField(name, expression string) *PivotTableBuilder {
+	ptb.config.DerivedFields = append(ptb.config.DerivedFields, PivotDerivedField{
+		Name:       name,
+		Type:       derivedCalculated,
+		Expression: expression,
+	})
+	return ptb
+}
+
+// deriveFields computes the values of the derived fields, which go to new
+// columns to the right of the source range, and returns header and rows
+// extended by them. Nothing is written to the sheet.
+func (ptb *PivotTableBuilder) deriveFields(header []string, rows [][]string) ([]string, [][]string, [][]interface{}, error) {
+	if len(ptb.config.DerivedFields) == 0 {
+		return header, rows, nil, nil
+	}
+
+	sheet, startCol, startRow, endCol, endRow, err := ptb.sourceLocation()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	isDateCell := ptb.dateCells(sheet, startCol, startRow)
+
+	derived := make([][]interface{}, len(ptb.config.DerivedFields))
+	for i, field := range ptb.config.DerivedFields {
+		if indexOf(header, field.Name) != -1 {
+			return nil, nil, nil, fmt.Errorf("derived field '%s' already exists in pivot source", field.Name)
+		}
+		values, err := deriveFieldValues(field, header, rows, isDateCell)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("derived field '%s': %w", field.Name, err)
+		}
+		if err := ptb.checkColumnEmpty(sheet, endCol+i+1, startRow, endRow); err != nil {
+			return nil, nil, nil, err
+		}
+		derived[i] = values
+
+		// Later derived fields may refer to this one
+		header = append(header, field.Name)
+		for r := range rows {
+			rows[r] = append(rows[r], formatDerivedValue(values[r]))
+		}
+	}
+	return header, rows, derived, nil
+}
+
+// applyDerivedFields writes the values of the derived fields computed by
+// deriveFields as new columns to the right of the source range and extends
+// the source range to include them
+func (ptb *PivotTableBuilder) applyDerivedFields(derived [][]interface{}) error {
+	if len(ptb.config.DerivedFields) == 0 {
+		return nil
+	}
+
+	sheet, startCol, startRow, endCol, endRow, err := ptb.sourceLocation()
+	if err != nil {
+		return err
+	}
+	for i, field := range ptb.config.DerivedFields {
+		if field.Type == derivedNumberGroup {
+			// Text labels would be listed in text order by Excel
+			if ptb.binItems == nil {
+				ptb.binItems = make(map[string][]string)
+			}
+			ptb.binItems[field.Name] = sortedBinLabels(derived[i])
+		}
+		col := endCol + i + 1
+		cell, _ := excelize.CoordinatesToCellName(col, startRow)
+		if err := ptb.file.SetCellValue(sheet, cell, field.Name); err != nil {
+			return fmt.Errorf("failed to write derived field '%s': %w", field.Name, err)
+		}
+		for r, value := range derived[i] {
+			cell, _ := excelize.CoordinatesToCellName(col, startRow+r+1)
+			if err := ptb.file.SetCellValue(sheet, cell, value); err != nil {
+				return fmt.Errorf("failed to write derived field '%s': %w", field.Name, err)
+			}
+		}
+	}
+
+	start, _ := excelize.CoordinatesToCellName(startCol, startRow)
+	end, _ := excelize.CoordinatesToCellName(endCol+len(ptb.config.DerivedFields), endRow)
+	ptb.config.SourceRange = fmt.Sprintf("%s!%s:%s", sheet, start, end)
+	ptb.config.SourceSheet = sheet
+	ptb.config.DerivedFields = nil
+	return nil
+}
+
+// dateCells returns a function reporting whether a source cell carries a
+// date number format. Its row and col are relative to the first data row
+// and the first column of the source range.
+func (ptb *PivotTableBuilder) dateCells(sheet string, startCol, startRow int) func(row, col int) bool {
+	dateStyles := make(map[int]bool)
+	return func(row, col int) bool {
+		cell, err := excelize.CoordinatesToCellName(startCol+col, startRow+row+1)
+		if err != nil {
+			return false
+		}
+		styleID, err := ptb.file.GetCellStyle(sheet, cell)
+		if err != nil {
+			return false
+		}
+		isDate, ok := dateStyles[styleID]
+		if !ok {
+			style, err := ptb.file.GetStyle(styleID)
+			isDate = err == nil && isDateNumFmt(style)
+			dateStyles[styleID] = isDate
+		}
+		return isDate
+	}
+}
+
+// isDateNumFmt reports whether the number format of a style shows a date
+// or a time
+func isDateNumFmt(style *excelize.Style) bool {
+	if style.CustomNumFmt == nil {
+		id := style.NumFmt
+		return (id >= 14 && id <= 22) || (id >= 27 && id <= 36) || (id >= 45 && id <= 47) ||
+			(id >= 50 && id <= 58) || (id >= 71 && id <= 81)
+	}
+
+	// Leave out quoted text, escaped characters and bracketed colors
+	var code strings.Builder
+	quoted := false
+	for i := 0; i < len(*style.CustomNumFmt); i++ {
+		switch ch := (*style.CustomNumFmt)[i]; {
+		case ch == '"':
+			quoted = !quoted
+		case quoted:
+		case ch == '\\':
+			i++
+		case ch == '[':
+			for i < len(*style.CustomNumFmt) && (*style.CustomNumFmt)[i] != ']' {
+				i++
+			}
+		default:
+			code.WriteByte(ch)
+		}
+	}
+	return strings.ContainsAny(strings.ToLower(code.String()), "ymdhs")
+}
+
+// checkColumnEmpty makes sure derived values do not overwrite existing data
+func (ptb *PivotTableBuilder) checkColumnEmpty(sheet string, col, startRow, endRow int) error {
+	for row := startRow; row <= endRow; row++ {
+		cell, _ := excelize.CoordinatesToCellName(col, row)
+		value, err := ptb.file.GetCellValue(sheet, cell)
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			return fmt.Errorf("cannot write derived field to %s!%s, cell is not empty", sheet, cell)
+		}
+	}
+	return nil
+}
+
+// deriveFieldValues computes the value of a derived field for every row.
+// isDateCell reports whether a cell carries a date number format.
+func deriveFieldValues(field PivotDerivedField, header []string, rows [][]string, isDateCell func(row, col int) bool) ([]interface{}, error) {
+	values := make([]interface{}, len(rows))
+
+	switch field.Type {
+	case derivedDateGroup, derivedNumberGroup:
+		col := indexOf(header, field.SourceField)
+		if col == -1 {
+			return nil, fmt.Errorf("source field '%s' not found", field.SourceField)
+		}
+		if field.Type == derivedNumberGroup && field.Interval <= 0 {
+			return nil, fmt.Errorf("interval must be positive, got %g", field.Interval)
+		}
+		for i, row := range rows {
+			if col >= len(row) || strings.TrimSpace(row[col]) == "" {
+				continue
+			}
+			var err error
+			if field.Type == derivedDateGroup {
+				values[i], err = groupDate(row[col], field.DateGrouping, isDateCell(i, col))
+			} else {
+				values[i], err = groupNumber(row[col], field.Start, field.Interval)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+2, err)
+			}
+		}
+	case derivedCalculated:
+		expr, err := parseFieldExpression(field.Expression, header)
+		if err != nil {
+			return nil, err
+		}
+		for i, row := range rows {
+			if result, ok := expr.eval(row); ok {
+				values[i] = result
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported derived field type '%s'", field.Type)
+	}
+
+	return values, nil
+}
+
+// groupDate returns the group label of a date cell value. serial reports
+// whether a number is an Excel date serial.
+func groupDate(value, grouping string, serial bool) (interface{}, error) {
+	date, err := parseDateValue(value, serial)
+	if err != nil {
+		return nil, err
+	}
+	switch grouping {
+	case "year":
+		return date.Year(), nil
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", date.Year(), (int(date.Month())-1)/3+1), nil
+	case "month":
+		return fmt.Sprintf("%d-%02d", date.Year(), int(date.Month())), nil
+	default:
+		return nil, fmt.Errorf("unsupported date grouping '%s', expected year, quarter or month", grouping)
+	}
+}
+
+// parseDateValue parses a text date, or an Excel date serial number when
+// serial is set. Numbers of cells without a date format are not dates.
+func parseDateValue(value string, serial bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		if !serial {
+			return time.Time{}, fmt.Errorf("cannot parse '%s' as a date, the cell has no date format", value)
+		}
+		return excelize.ExcelDateToTime(number, false)
+	}
+	return time.Time{}, fmt.Errorf("cannot parse '%s' as a date", value)
+}
+
+// numberBin is a bin of a number group
+type numberBin struct {
+	lower, upper float64
+}
+
+// String returns the label of the bin
+func (b numberBin) String() string {
+	return fmt.Sprintf("[%g, %g)", b.lower, b.upper)
+}
+
+// groupNumber returns the bin of a numeric cell value
+func groupNumber(value string, start, interval float64) (interface{}, error) {
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse '%s' as a number", value)
+	}
+	lower := start + math.Floor((number-start)/interval)*interval
+	return numberBin{lower: lower, upper: lower + interval}, nil
+}
+
+// sortedBinLabels returns the labels of the distinct bins of values in
+// numeric order
+func sortedBinLabels(values []interface{}) []string {
+	var bins []numberBin
+	seen := make(map[numberBin]bool)
+	for _, value := range values {
+		if bin, ok := value.(numberBin); ok && !seen[bin] {
+			seen[bin] = true
+			bins = append(bins, bin)
+		}
+	}
+	sort.Slice(bins, func(i, j int) bool { return bins[i].lower < bins[j].lower })
+	labels := make([]string, len(bins))
+	for i, bin := range bins {
+		labels[i] = bin.String()
+	}
+	return labels
+}
+
+// formatDerivedValue converts a derived value to its raw cell text
+func formatDerivedValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// fieldExpr is a node of a parsed calculated field expression
+type fieldExpr interface {
+	eval(row []string) (float64, bool)
+}
+
+type numberExpr float64
+
+type columnExpr int
+
+type unaryExpr struct {
+	operand fieldExpr
+}
+
+type binaryExpr struct {
+	op          rune
+	left, right fieldExpr
+}
+
+func (e numberExpr) eval(row []string) (float64, bool) {
+	return float64(e), true
+}
+
+func (e columnExpr) eval(row []string) (float64, bool) {
+	if int(e) >= len(row) {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(row[e]), 64)
+	return value, err == nil
+}
+
+func (e unaryExpr) eval(row []string) (float64, bool) {
+	value, ok := e.operand.eval(row)
+	return -value, ok
+}
+
+func (e binaryExpr) eval(row []string) (float64, bool) {
+	left, ok := e.left.eval(row)
+	if !ok {
+		return 0, false
+	}
+	right, ok := e.right.eval(row)
+	if !ok {
+		return 0, false
+	}
+	switch e.op {
+	case '+':
+		return left + right, true
+	case '-':
+		return left - right, true
+	case '*':
+		return left * right, true
+	default:
+		if right == 0 {
+			return 0, false
+		}
+		return left / right, true
+	}
+}
+
+// expressionParser is a recursive descent parser for calculated fields:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = number | field | "[" field "]" | "(" expr ")" | "-" factor
+type expressionParser struct {
+	input  []rune
+	pos    int
+	header []string
+}
+
+// parseFieldExpression parses an expression whose identifiers are header fields
+func parseFieldExpression(expression string, header []string) (fieldExpr, error) {
+	p := &expressionParser{input: []rune(expression), header: header}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression '%s': %w", expression, err)
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("invalid expression '%s': unexpected '%c' at position %d", expression, p.input[p.pos], p.pos+1)
+	}
+	return expr, nil
+}
+
+func (p *expressionParser) parseExpr() (fieldExpr, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '+' && p.input[p.pos] != '-') {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *expressionParser) parseTerm() (fieldExpr, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '*' && p.input[p.pos] != '/') {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *expressionParser) parseFactor() (fieldExpr, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	switch ch := p.input[p.pos]; {
+	case ch == '-':
+		p.pos++
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return unaryExpr{operand: operand}, nil
+	case ch == '(':
+		p.pos++
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return expr, nil
+	case ch == '[':
+		start := p.pos + 1
+		for p.pos < len(p.input) && p.input[p.pos] != ']' {
+			p.pos++
+		}
+		if p.pos >= len(p.input) {
+			return nil, fmt.Errorf("missing closing bracket")
+		}
+		name := string(p.input[start:p.pos])
+		p.pos++
+		return p.column(name)
+	case unicode.IsDigit(ch) || ch == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(string(p.input[start:p.pos]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s'", string(p.input[start:p.pos]))
+		}
+		return numberExpr(value), nil
+	case unicode.IsLetter(ch) || ch == '_':
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsLetter(p.input[p.pos]) || unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '_') {
+			p.pos++
+		}
+		return p.column(string(p.input[start:p.pos]))
+	default:
+		return nil, fmt.Errorf("unexpected '%c' at position %d", ch, p.pos+1)
+	}
+}
+
+// column resolves a field name to its column index
+func (p *expressionParser) column(name string) (fieldExpr, error) {
+	col := indexOf(p.header, name)
+	if col == -1 {
+		return nil, fmt.Errorf("field '%s' not found", name)
+	}
+	return columnExpr(col), nil
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
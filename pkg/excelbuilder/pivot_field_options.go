@@ -0,0 +1,335 @@
+package excelbuilder
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// showValuesAsTypes maps the ShowValuesAs option to the OOXML showDataAs attribute
+var showValuesAsTypes = map[string]string{
+	"normal":            "",
+	"percentOfTotal":    "percentOfTotal",
+	"percentOfRow":      "percentOfRow",
+	"percentOfColumn":   "percentOfCol",
+	"percentOf":         "percent",
+	"difference":        "difference",
+	"percentDifference": "percentDiff",
+	"runningTotal":      "runTotal",
+	"index":             "index",
+}
+
+// pivotPreviousItem is the special base item index meaning "previous item"
+const pivotPreviousItem = 1048828
+
+// applyFieldOptions patches the pivot table parts written by excelize with
+// the field options excelize has no API for: sort order, hidden items,
+// "show values as" and custom number formats. It also lists the items of
+// number groups in numeric order.
+func (ptb *PivotTableBuilder) applyFieldOptions() error {
+	if !ptb.hasFieldOptions() {
+		return nil
+	}
+
+	header, rows, err := ptb.sourceData()
+	if err != nil {
+		return err
+	}
+
+	tablePath, cachePath, err := pivotTableParts(ptb.file, ptb.config.TargetSheet, ptb.config.Name)
+	if err != nil {
+		return err
+	}
+	table, err := loadXMLPart(ptb.file, tablePath)
+	if err != nil {
+		return err
+	}
+	cache, err := loadXMLPart(ptb.file, cachePath)
+	if err != nil {
+		return err
+	}
+	pivotFields := table.root().element("pivotFields").elements("pivotField")
+	cacheFields := cache.root().element("cacheFields").elements("cacheField")
+
+	for _, field := range append(append([]PivotField{}, ptb.config.RowFields...), ptb.config.ColumnFields...) {
+		bins := ptb.binItems[field.Name]
+		if field.Options.Sort == "" && len(field.Options.HiddenItems) == 0 && bins == nil {
+			continue
+		}
+		// Pivot fields follow the order of the cache fields
+		index := pivotCacheFieldIndex(cacheFields, field.Name)
+		if index == -1 || index >= len(pivotFields) {
+			return fmt.Errorf("pivot field '%s' not found", field.Name)
+		}
+		pivotField, cacheField := pivotFields[index], cacheFields[index]
+
+		if field.Options.Sort != "" {
+			if field.Options.Sort != "ascending" && field.Options.Sort != "descending" {
+				return fmt.Errorf("invalid sort order '%s' for field '%s'", field.Options.Sort, field.Name)
+			}
+			pivotField.setAttr("sortType", field.Options.Sort)
+		}
+		if len(field.Options.HiddenItems) > 0 || bins != nil {
+			items := bins
+			if items == nil {
+				col := indexOf(header, field.Name)
+				if col == -1 {
+					return fmt.Errorf("field '%s' not found in pivot source", field.Name)
+				}
+				items = uniqueColumnValues(rows, col)
+			}
+			if err := setPivotFieldItems(pivotField, field.Name, items, field.Options.HiddenItems); err != nil {
+				return err
+			}
+			if err := setCacheFieldItems(cacheField, field.Name, items); err != nil {
+				return err
+			}
+		}
+	}
+
+	dataFields := table.root().element("dataFields").elements("dataField")
+	for i, field := range ptb.config.ValueFields {
+		attrs, err := ptb.dataFieldAttrs(field, header)
+		if err != nil {
+			return err
+		}
+		if len(attrs) == 0 {
+			continue
+		}
+		if i >= len(dataFields) {
+			return fmt.Errorf("data field '%s' not found", field.Name)
+		}
+		for _, attr := range attrs {
+			dataFields[i].setAttr(attr.Name.Local, attr.Value)
+		}
+	}
+
+	if err := storeXMLPart(ptb.file, tablePath, table); err != nil {
+		return err
+	}
+	return storeXMLPart(ptb.file, cachePath, cache)
+}
+
+// hasFieldOptions reports whether any field uses options that require patching
+func (ptb *PivotTableBuilder) hasFieldOptions() bool {
+	for _, field := range append(append([]PivotField{}, ptb.config.RowFields...), ptb.config.ColumnFields...) {
+		if field.Options.Sort != "" || len(field.Options.HiddenItems) > 0 || ptb.binItems[field.Name] != nil {
+			return true
+		}
+	}
+	for _, field := range ptb.config.ValueFields {
+		if field.ValueOptions.NumberFormat != "" || field.ValueOptions.ShowValuesAs != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// dataFieldAttrs returns the extra attributes of a data field element
+func (ptb *PivotTableBuilder) dataFieldAttrs(field PivotField, header []string) ([]xml.Attr, error) {
+	var attrs []xml.Attr
+	opts := field.ValueOptions
+
+	if opts.ShowValuesAs != "" {
+		showDataAs, ok := showValuesAsTypes[opts.ShowValuesAs]
+		if !ok {
+			return nil, fmt.Errorf("invalid show values as '%s' for field '%s'", opts.ShowValuesAs, field.Name)
+		}
+		if showDataAs != "" {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "showDataAs"}, Value: showDataAs})
+		}
+		switch showDataAs {
+		case "runTotal", "difference", "percent", "percentDiff":
+			baseField := indexOf(header, opts.BaseField)
+			if baseField == -1 {
+				return nil, fmt.Errorf("show values as '%s' for field '%s' requires a valid base field, got '%s'", opts.ShowValuesAs, field.Name, opts.BaseField)
+			}
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "baseField"}, Value: strconv.Itoa(baseField)})
+			if showDataAs != "runTotal" {
+				attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "baseItem"}, Value: strconv.Itoa(pivotPreviousItem)})
+			}
+		}
+	}
+
+	if opts.NumberFormat != "" {
+		numFmtID, err := customNumFmtID(ptb.file, opts.NumberFormat)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number format '%s' for field '%s': %w", opts.NumberFormat, field.Name, err)
+		}
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "numFmtId"}, Value: strconv.Itoa(numFmtID)})
+	}
+
+	return attrs, nil
+}
+
+// sourceData reads the header and data rows of the pivot source range
+func (ptb *PivotTableBuilder) sourceData(opts ...excelize.Options) ([]string, [][]string, error) {
+	sheet, startCol, startRow, endCol, endRow, err := ptb.sourceLocation()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := ptb.file.GetRows(sheet, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read pivot source sheet '%s': %w", sheet, err)
+	}
+
+	var data [][]string
+	for r := startRow; r <= endRow; r++ {
+		row := make([]string, endCol-startCol+1)
+		if r <= len(rows) {
+			for c := startCol; c <= endCol; c++ {
+				if c <= len(rows[r-1]) {
+					row[c-startCol] = rows[r-1][c-1]
+				}
+			}
+		}
+		data = append(data, row)
+	}
+	return data[0], data[1:], nil
+}
+
+// sourceLocation parses the pivot source range into its sheet and coordinates
+func (ptb *PivotTableBuilder) sourceLocation() (sheet string, startCol, startRow, endCol, endRow int, err error) {
+	sheet, cellRange := ptb.config.SourceSheet, ptb.config.SourceRange
+	if idx := strings.LastIndex(cellRange, "!"); idx != -1 {
+		sheet = strings.Trim(cellRange[:idx], "'")
+		cellRange = cellRange[idx+1:]
+	}
+	startCol, startRow, endCol, endRow, err = parseCellRange(strings.ReplaceAll(cellRange, "$", ""))
+	if err != nil {
+		return "", 0, 0, 0, 0, fmt.Errorf("invalid pivot source range '%s': %w", ptb.config.SourceRange, err)
+	}
+	return sheet, startCol, startRow, endCol, endRow, nil
+}
+
+// pivotCacheFieldIndex returns the index of the cache field of a field, or -1
+func pivotCacheFieldIndex(cacheFields []*xmlNode, fieldName string) int {
+	for i, cacheField := range cacheFields {
+		if cacheField.attr("name") == fieldName {
+			return i
+		}
+	}
+	return -1
+}
+
+// setPivotFieldItems replaces the items of a pivot field, hiding the given ones
+func setPivotFieldItems(pivotField *xmlNode, fieldName string, items, hidden []string) error {
+	old := pivotField.element("items")
+	if old == nil {
+		return fmt.Errorf("pivot field '%s' has no items", fieldName)
+	}
+
+	node := &xmlNode{name: old.name}
+	node.setAttr("count", strconv.Itoa(len(items)+1))
+	for i, item := range items {
+		child := &xmlNode{name: "item"}
+		if indexOf(hidden, item) != -1 {
+			child.setAttr("h", "1")
+		}
+		child.setAttr("x", strconv.Itoa(i))
+		node.children = append(node.children, child)
+	}
+	total := &xmlNode{name: "item"}
+	total.setAttr("t", "default")
+	node.children = append(node.children, total)
+
+	pivotField.replace(old, node)
+	return nil
+}
+
+// setCacheFieldItems writes the shared items of a cache field so that pivot
+// field items can refer to them by index
+func setCacheFieldItems(cacheField *xmlNode, fieldName string, items []string) error {
+	old := cacheField.element("sharedItems")
+	if old == nil {
+		return fmt.Errorf("pivot cache field '%s' has no shared items", fieldName)
+	}
+
+	numeric := true
+	for _, item := range items {
+		if _, err := strconv.ParseFloat(item, 64); err != nil {
+			numeric = false
+			break
+		}
+	}
+
+	node := &xmlNode{name: old.name}
+	itemName := "s"
+	if numeric {
+		node.setAttr("containsSemiMixedTypes", "0")
+		node.setAttr("containsString", "0")
+		node.setAttr("containsNumber", "1")
+		itemName = "n"
+	}
+	node.setAttr("count", strconv.Itoa(len(items)))
+	for _, item := range items {
+		child := &xmlNode{name: itemName}
+		child.setAttr("v", item)
+		node.children = append(node.children, child)
+	}
+
+	cacheField.replace(old, node)
+	return nil
+}
+
+// customNumFmtID registers a custom number format and returns its ID
+func customNumFmtID(file *excelize.File, format string) (int, error) {
+	styleID, err := file.NewStyle(&excelize.Style{CustomNumFmt: &format})
+	if err != nil {
+		return 0, err
+	}
+	numFmtID := file.Styles.CellXfs.Xf[styleID].NumFmtID
+	if numFmtID == nil {
+		return 0, fmt.Errorf("number format was not registered")
+	}
+	return *numFmtID, nil
+}
+
+// uniqueColumnValues returns the distinct values of a column in order of appearance
+func uniqueColumnValues(rows [][]string, col int) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, row := range rows {
+		if col < len(row) && !seen[row[col]] {
+			seen[row[col]] = true
+			values = append(values, row[col])
+		}
+	}
+	return values
+}
+
+// countParts counts the package parts whose path starts with prefix
+func countParts(file *excelize.File, prefix string) int {
+	count := 0
+	file.Pkg.Range(func(key, _ interface{}) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+// loadPart returns the content of a package part
+func loadPart(file *excelize.File, path string) ([]byte, bool) {
+	content, ok := file.Pkg.Load(path)
+	if !ok {
+		return nil, false
+	}
+	data, ok := content.([]byte)
+	return data, ok
+}
+
+// indexOf returns the index of value in values, or -1
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -11,6 +11,7 @@ type PivotTableBuilder struct {
 	sheetBuilder *SheetBuilder
 	file         *excelize.File
 	config       PivotTableConfig
+	binItems     map[string][]string // Items of number groups, in numeric order
 }
 
 // NewPivotTableBuilder creates a new PivotTableBuilder instance
@@ -67,6 +68,15 @@ func (ptb *PivotTableBuilder) AddRowField(fieldName string) *PivotTableBuilder {
 	return ptb
 }
 
+// AddRowFieldWithOptions adds a field to the row area with sorting and item filtering
+func (ptb *PivotTableBuilder) AddRowFieldWithOptions(fieldName string, opts PivotFieldOptions) *PivotTableBuilder {
+	ptb.config.RowFields = append(ptb.config.RowFields, PivotField{
+		Name:    fieldName,
+		Options: opts,
+	})
+	return ptb
+}
+
 // AddColumnField adds a field to the column area of the pivot table
 func (ptb *PivotTableBuilder) AddColumnField(fieldName string) *PivotTableBuilder {
 	field := PivotField{
@@ -77,6 +87,15 @@ func (ptb *PivotTableBuilder) AddColumnField(fieldName string) *PivotTableBuilde
 	return ptb
 }
 
+// AddColumnFieldWithOptions adds a field to the column area with sorting and item filtering
+func (ptb *PivotTableBuilder) AddColumnFieldWithOptions(fieldName string, opts PivotFieldOptions) *PivotTableBuilder {
+	ptb.config.ColumnFields = append(ptb.config.ColumnFields, PivotField{
+		Name:    fieldName,
+		Options: opts,
+	})
+	return ptb
+}
+
 // AddValueField adds a field to the value area of the pivot table with aggregation function
 func (ptb *PivotTableBuilder) AddValueField(fieldName, function string) *PivotTableBuilder {
 	field := PivotField{
@@ -87,6 +106,17 @@ func (ptb *PivotTableBuilder) AddValueField(fieldName, function string) *PivotTa
 	return ptb
 }
 
+// AddValueFieldWithOptions adds a field to the value area with a display name,
+// number format and "show values as" calculation
+func (ptb *PivotTableBuilder) AddValueFieldWithOptions(fieldName, function string, opts PivotValueFieldOptions) *PivotTableBuilder {
+	ptb.config.ValueFields = append(ptb.config.ValueFields, PivotField{
+		Name:         fieldName,
+		Function:     function,
+		ValueOptions: opts,
+	})
+	return ptb
+}
+
 // AddFilterField adds a field to the filter area of the pivot table
 func (ptb *PivotTableBuilder) AddFilterField(fieldName string) *PivotTableBuilder {
 	field := PivotField{
@@ -138,8 +168,16 @@ func (ptb *PivotTableBuilder) GetConfig() PivotTableConfig {
 	return ptb.config
 }
 
-// Build creates the pivot table in the Excel file
+// Build validates the fields (see Validate) and creates the pivot table in the Excel file
 func (ptb *PivotTableBuilder) Build() error {
+	derived, err := ptb.validate()
+	if err != nil {
+		return err
+	}
+	if err := ptb.applyDerivedFields(derived); err != nil {
+		return err
+	}
+
 	// Create the target sheet if it doesn't exist
 	if ptb.config.TargetSheet != "" {
 		// Check if sheet exists
@@ -164,11 +202,19 @@ func (ptb *PivotTableBuilder) Build() error {
 	options := ptb.buildPivotTableOptions()
 
 	// Create the pivot table using excelize
-	err := ptb.file.AddPivotTable(&options)
-	if err != nil {
+	if err := ptb.file.AddPivotTable(&options); err != nil {
 		return fmt.Errorf("failed to create pivot table: %w", err)
 	}
 
+	// Apply the field options excelize does not support natively
+	if err := ptb.applyFieldOptions(); err != nil {
+		return fmt.Errorf("failed to apply pivot table field options: %w", err)
+	}
+
+	if err := ptb.addSlicers(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -222,9 +268,13 @@ func (ptb *PivotTableBuilder) buildFieldOptions(fields []PivotField) []excelize.
 func (ptb *PivotTableBuilder) buildDataFieldOptions(fields []PivotField) []excelize.PivotTableField {
 	result := make([]excelize.PivotTableField, len(fields))
 	for i, field := range fields {
+		name := field.ValueOptions.DisplayName
+		if name == "" {
+			name = fmt.Sprintf("%s of %s", field.Function, field.Name)
+		}
 		result[i] = excelize.PivotTableField{
 			Data:     field.Name,
-			Name:     fmt.Sprintf("%s of %s", field.Function, field.Name),
+			Name:     name,
 			Subtotal: field.Function,
 		}
 	}
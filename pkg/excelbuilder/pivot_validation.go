@@ -0,0 +1,164 @@
+package excelbuilder
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Errors reported by PivotTableBuilder.Validate, wrapped in a PivotFieldError
+var (
+	ErrPivotFieldNotFound    = errors.New("field not found in source header")
+	ErrPivotFieldDuplicate   = errors.New("field used in more than one role")
+	ErrPivotFunctionInvalid  = errors.New("invalid value field function")
+	ErrPivotFunctionMismatch = errors.New("value field function requires a numeric column")
+)
+
+// PivotFieldError reports an invalid pivot table field
+type PivotFieldError struct {
+	Field  string
+	Role   string // "row", "column", "value" or "filter"
+	Detail string
+	Err    error
+}
+
+// Error implements the error interface
+func (e *PivotFieldError) Error() string {
+	msg := fmt.Sprintf("pivot %s field '%s': %v", e.Role, e.Field, e.Err)
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error so errors.Is can match it
+func (e *PivotFieldError) Unwrap() error {
+	return e.Err
+}
+
+// pivotFunctions lists the value field functions supported by excelize
+var pivotFunctions = []string{"average", "count", "countNums", "max", "min", "product", "stdDev", "stdDevp", "sum", "var", "varp"}
+
+// Validate checks the configured fields against the header row of the source
+// range and the names of the derived fields. Every field must exist, row,
+// column and filter fields must not be shared between those roles, and value
+// field functions other than "count" and "countNums" require a numeric
+// column. Derived fields are computed but not written.
+func (ptb *PivotTableBuilder) Validate() error {
+	_, err := ptb.validate()
+	return err
+}
+
+// validate implements Validate and returns the values of the derived fields
+func (ptb *PivotTableBuilder) validate() ([][]interface{}, error) {
+	header, rows, err := ptb.sourceData(excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+	header, rows, derived, err := ptb.deriveFields(header, rows)
+	if err != nil {
+		return nil, err
+	}
+	return derived, ptb.validateFields(header, rows)
+}
+
+// validateFields checks the configured fields against the source header and rows
+func (ptb *PivotTableBuilder) validateFields(header []string, rows [][]string) error {
+	roles := make(map[string]string)
+	axes := []struct {
+		role   string
+		fields []PivotField
+	}{
+		{"row", ptb.config.RowFields},
+		{"column", ptb.config.ColumnFields},
+		{"filter", ptb.config.FilterFields},
+	}
+	for _, axis := range axes {
+		for _, field := range axis.fields {
+			if err := checkPivotFieldExists(header, field.Name, axis.role); err != nil {
+				return err
+			}
+			if role, used := roles[field.Name]; used {
+				return &PivotFieldError{
+					Field:  field.Name,
+					Role:   axis.role,
+					Detail: fmt.Sprintf("already used as %s field", role),
+					Err:    ErrPivotFieldDuplicate,
+				}
+			}
+			roles[field.Name] = axis.role
+		}
+	}
+
+	for _, field := range ptb.config.ValueFields {
+		if err := checkPivotFieldExists(header, field.Name, "value"); err != nil {
+			return err
+		}
+		function, ok := normalizePivotFunction(field.Function)
+		if !ok {
+			return &PivotFieldError{
+				Field:  field.Name,
+				Role:   "value",
+				Detail: fmt.Sprintf("'%s' is not one of %s", field.Function, strings.Join(pivotFunctions, ", ")),
+				Err:    ErrPivotFunctionInvalid,
+			}
+		}
+		// Counting numbers of a text column is valid and gives 0
+		if function != "count" && function != "countNums" && !isNumericColumn(rows, indexOf(header, field.Name)) {
+			return &PivotFieldError{
+				Field:  field.Name,
+				Role:   "value",
+				Detail: fmt.Sprintf("'%s' cannot be applied to text values, use 'count'", function),
+				Err:    ErrPivotFunctionMismatch,
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkPivotFieldExists returns a PivotFieldError when name is not in header
+func checkPivotFieldExists(header []string, name, role string) error {
+	if indexOf(header, name) != -1 {
+		return nil
+	}
+	err := &PivotFieldError{Field: name, Role: role, Err: ErrPivotFieldNotFound}
+	for _, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), strings.TrimSpace(name)) {
+			err.Detail = fmt.Sprintf("did you mean '%s'?", column)
+			return err
+		}
+	}
+	err.Detail = fmt.Sprintf("available fields: %s", strings.Join(header, ", "))
+	return err
+}
+
+// normalizePivotFunction returns the canonical name of a value field function.
+// An empty function defaults to "sum".
+func normalizePivotFunction(function string) (string, bool) {
+	if function == "" {
+		return "sum", true
+	}
+	for _, f := range pivotFunctions {
+		if strings.EqualFold(f, function) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// isNumericColumn reports whether every non-empty value of a column is a number
+func isNumericColumn(rows [][]string, col int) bool {
+	for _, row := range rows {
+		if col >= len(row) || row[col] == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(row[col], 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -12,6 +12,9 @@ type RowBuilder struct {
 	rowIndex     int
 	currentCol   int
 	hasError     bool
+	// First and last columns holding numeric values, used by AddSparkline
+	firstNumericCol int
+	lastNumericCol  int
 }
 
 // AddCell adds a cell with the given value and returns a CellBuilder
@@ -41,6 +44,13 @@ func (rb *RowBuilder) AddCell(value interface{}) *CellBuilder {
 		}
 	}
 
+	if isNumericValue(value) {
+		if rb.firstNumericCol == 0 {
+			rb.firstNumericCol = rb.currentCol
+		}
+		rb.lastNumericCol = rb.currentCol
+	}
+
 	return &CellBuilder{
 		rowBuilder:   rb,
 		sheetBuilder: rb.sheetBuilder,
@@ -75,7 +85,39 @@ func (rb *RowBuilder) SetHeight(height float64) *RowBuilder {
 	return rb
 }
 
+// AddSparkline adds a cell holding a sparkline that summarizes the numeric
+// cells added to the row so far.
+func (rb *RowBuilder) AddSparkline(opts SparklineOptions) *RowBuilder {
+	rb.currentCol++
+	if rb.firstNumericCol == 0 {
+		rb.sheetBuilder.workbookBuilder.excelBuilder.AddError(fmt.Errorf("row %d has no numeric cells to summarize in a sparkline", rb.rowIndex))
+		rb.hasError = true
+		return rb
+	}
+
+	location, _ := excelize.CoordinatesToCellName(rb.currentCol, rb.rowIndex)
+	start, _ := excelize.CoordinatesToCellName(rb.firstNumericCol, rb.rowIndex)
+	end, _ := excelize.CoordinatesToCellName(rb.lastNumericCol, rb.rowIndex)
+	dataRange := fmt.Sprintf("'%s'!%s:%s", rb.sheetBuilder.sheetName, start, end)
+
+	if err := rb.sheetBuilder.addSparklines([]string{location}, []string{dataRange}, opts); err != nil {
+		rb.sheetBuilder.workbookBuilder.excelBuilder.AddError(fmt.Errorf("failed to add sparkline at %s: %w", location, err))
+		rb.hasError = true
+	}
+	return rb
+}
+
 // Done returns to the SheetBuilder
 func (rb *RowBuilder) Done() *SheetBuilder {
 	return rb.sheetBuilder
 }
+
+// isNumericValue reports whether a cell value is a Go numeric type
+func isNumericValue(value interface{}) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -539,6 +539,11 @@ func (sb *SheetBuilder) NewPivotTable(targetSheet, sourceRange string) *PivotTab
 	return NewPivotTableBuilder(sb, targetSheet, sourceRange)
 }
 
+// AddTable creates a new TableBuilder for cellRange, including the header row
+func (sb *SheetBuilder) AddTable(cellRange string) *TableBuilder {
+	return NewTableBuilder(sb, cellRange)
+}
+
 // GetLayoutManager returns an AdvancedLayoutManager for this sheet
 func (sb *SheetBuilder) GetLayoutManager() *AdvancedLayoutManager {
 	return NewAdvancedLayoutManager(sb)
@@ -0,0 +1,166 @@
+package excelbuilder
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// AddSlicer adds a slicer for field at cell. The slicer is created when the
+// pivot table is built and placed on the pivot table's sheet unless
+// opts.Sheet is set.
+func (ptb *PivotTableBuilder) AddSlicer(field, cell string, opts SlicerOptions) *PivotTableBuilder {
+	ptb.config.Slicers = append(ptb.config.Slicers, SlicerConfig{
+		Field:   field,
+		Cell:    cell,
+		Options: opts,
+	})
+	return ptb
+}
+
+// addSlicers adds the configured slicers to the built pivot table
+func (ptb *PivotTableBuilder) addSlicers() error {
+	if len(ptb.config.Slicers) == 0 {
+		return nil
+	}
+
+	// excelize reads the pivot tables of the sheet back when adding a slicer
+	// and cannot parse pivot fields carrying a name. The names only repeat
+	// the source header, so drop them.
+	if err := dropPivotFieldNames(ptb.file, ptb.config.TargetSheet); err != nil {
+		return err
+	}
+
+	for _, slicer := range ptb.config.Slicers {
+		restore, err := ptb.hideSlicerCaches(slicer.Field)
+		if err != nil {
+			return err
+		}
+		err = addSlicer(ptb.file, ptb.config.TargetSheet, ptb.config.Name, slicer)
+		restore()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropPivotFieldNames removes the name attribute of the pivot fields of the
+// pivot tables on sheet
+func dropPivotFieldNames(file *excelize.File, sheet string) error {
+	sheetPath, _, err := sheetPart(file, sheet)
+	if err != nil {
+		return err
+	}
+	tables, err := relatedParts(file, sheetPath, relTypePivotTable)
+	if err != nil {
+		return err
+	}
+	for _, tablePath := range tables {
+		table, err := loadXMLPart(file, tablePath)
+		if err != nil {
+			return err
+		}
+		for _, field := range table.root().element("pivotFields").elements("pivotField") {
+			field.removeAttr("name")
+		}
+		if err := storeXMLPart(file, tablePath, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hideSlicerCaches detaches the pivot table name from every slicer cache
+// but the ones of field on this pivot table until the returned function is
+// called. excelize reuses any slicer cache listing a pivot table of the same
+// name, on any sheet, which would make every slicer filter the first field.
+func (ptb *PivotTableBuilder) hideSlicerCaches(field string) (func(), error) {
+	workbook, err := workbookPart(ptb.file)
+	if err != nil {
+		return nil, err
+	}
+	_, sheetID, err := sheetPart(ptb.file, ptb.config.TargetSheet)
+	if err != nil {
+		return nil, err
+	}
+	caches, err := relatedParts(ptb.file, workbook, relTypeSlicerCache)
+	if err != nil {
+		return nil, err
+	}
+
+	originals := make(map[string][]byte)
+	restore := func() {
+		for path, cache := range originals {
+			ptb.file.Pkg.Store(path, cache)
+		}
+	}
+	for _, path := range caches {
+		original, ok := loadPart(ptb.file, path)
+		if !ok {
+			continue
+		}
+		cache, err := parseXMLPart(original)
+		if err != nil {
+			restore()
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		root := cache.root()
+		pivotTables := root.element("pivotTables")
+		hidden := false
+		for _, pivotTable := range pivotTables.elements("pivotTable") {
+			if pivotTable.attr("name") != ptb.config.Name {
+				continue
+			}
+			if root.attr("sourceName") == field && pivotTable.attr("tabId") == strconv.Itoa(sheetID) {
+				continue
+			}
+			pivotTables.remove(pivotTable)
+			hidden = true
+		}
+		if !hidden {
+			continue
+		}
+		if err := storeXMLPart(ptb.file, path, cache); err != nil {
+			restore()
+			return nil, err
+		}
+		originals[path] = original
+	}
+	return restore, nil
+}
+
+// addSlicer adds a slicer for a field of the named table or pivot table on sheet
+func addSlicer(file *excelize.File, sheet, tableName string, slicer SlicerConfig) error {
+	if slicer.Field == "" || slicer.Cell == "" {
+		return fmt.Errorf("slicer requires a field and a cell")
+	}
+
+	caption := slicer.Options.Caption
+	if caption == "" {
+		caption = slicer.Field
+	}
+	target := slicer.Options.Sheet
+	if target == "" {
+		target = sheet
+	}
+	displayHeader := !slicer.Options.HideHeader
+
+	err := file.AddSlicer(target, &excelize.SlicerOptions{
+		Name:          slicer.Field,
+		Cell:          slicer.Cell,
+		TableSheet:    sheet,
+		TableName:     tableName,
+		Caption:       caption,
+		Width:         slicer.Options.Width,
+		Height:        slicer.Options.Height,
+		DisplayHeader: &displayHeader,
+		ItemDesc:      slicer.Options.Descending,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add slicer for field '%s': %w", slicer.Field, err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package excelbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// AddSparklines adds one sparkline per cell of locationRange (e.g. "F2:F10").
+// Each sparkline plots the matching row of dataRange (e.g. "B2:E10"), or the
+// matching column when locationRange is a single row. dataRange may be
+// prefixed with a sheet name; otherwise the current sheet is used.
+func (sb *SheetBuilder) AddSparklines(locationRange, dataRange string, opts SparklineOptions) *SheetBuilder {
+	locations, ranges, err := splitSparklineRanges(sb.sheetName, locationRange, dataRange)
+	if err != nil {
+		sb.workbookBuilder.excelBuilder.AddError(err)
+		sb.hasError = true
+		return sb
+	}
+
+	if err := sb.addSparklines(locations, ranges, opts); err != nil {
+		sb.workbookBuilder.excelBuilder.AddError(fmt.Errorf("failed to add sparklines to %s: %w", locationRange, err))
+		sb.hasError = true
+	}
+	return sb
+}
+
+// addSparklines adds a sparkline group to the sheet
+func (sb *SheetBuilder) addSparklines(locations, ranges []string, opts SparklineOptions) error {
+	return sb.workbookBuilder.file.AddSparkline(sb.sheetName, &excelize.SparklineOptions{
+		Location:      locations,
+		Range:         ranges,
+		Type:          opts.Type,
+		Style:         opts.Style,
+		Markers:       opts.Markers,
+		High:          opts.High,
+		Low:           opts.Low,
+		First:         opts.First,
+		Last:          opts.Last,
+		Negative:      opts.Negative,
+		Axis:          opts.Axis,
+		Reverse:       opts.Reverse,
+		SeriesColor:   opts.SeriesColor,
+		NegativeColor: opts.NegativeColor,
+		MarkersColor:  opts.MarkersColor,
+		FirstColor:    opts.FirstColor,
+		LastColor:     opts.LastColor,
+		HightColor:    opts.HighColor,
+		LowColor:      opts.LowColor,
+	})
+}
+
+// splitSparklineRanges pairs every location cell with its slice of the data range
+func splitSparklineRanges(sheetName, locationRange, dataRange string) ([]string, []string, error) {
+	locStartCol, locStartRow, locEndCol, locEndRow, err := parseCellRange(locationRange)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid sparkline location range '%s': %w", locationRange, err)
+	}
+	if locStartCol != locEndCol && locStartRow != locEndRow {
+		return nil, nil, fmt.Errorf("sparkline location range '%s' must be a single row or column", locationRange)
+	}
+
+	dataSheet := sheetName
+	if idx := strings.LastIndex(dataRange, "!"); idx != -1 {
+		dataSheet = strings.Trim(dataRange[:idx], "'")
+		dataRange = dataRange[idx+1:]
+	}
+	dataStartCol, dataStartRow, dataEndCol, dataEndRow, err := parseCellRange(dataRange)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid sparkline data range '%s': %w", dataRange, err)
+	}
+
+	var locations, ranges []string
+	if locStartCol == locEndCol {
+		// Vertical location range: one data row per location cell
+		if locEndRow-locStartRow != dataEndRow-dataStartRow {
+			return nil, nil, fmt.Errorf("sparkline location range '%s' and data range '%s' must have the same number of rows", locationRange, dataRange)
+		}
+		for i := 0; i <= locEndRow-locStartRow; i++ {
+			location, _ := excelize.CoordinatesToCellName(locStartCol, locStartRow+i)
+			start, _ := excelize.CoordinatesToCellName(dataStartCol, dataStartRow+i)
+			end, _ := excelize.CoordinatesToCellName(dataEndCol, dataStartRow+i)
+			locations = append(locations, location)
+			ranges = append(ranges, fmt.Sprintf("'%s'!%s:%s", dataSheet, start, end))
+		}
+	} else {
+		// Horizontal location range: one data column per location cell
+		if locEndCol-locStartCol != dataEndCol-dataStartCol {
+			return nil, nil, fmt.Errorf("sparkline location range '%s' and data range '%s' must have the same number of columns", locationRange, dataRange)
+		}
+		for i := 0; i <= locEndCol-locStartCol; i++ {
+			location, _ := excelize.CoordinatesToCellName(locStartCol+i, locStartRow)
+			start, _ := excelize.CoordinatesToCellName(dataStartCol+i, dataStartRow)
+			end, _ := excelize.CoordinatesToCellName(dataStartCol+i, dataEndRow)
+			locations = append(locations, location)
+			ranges = append(ranges, fmt.Sprintf("'%s'!%s:%s", dataSheet, start, end))
+		}
+	}
+
+	return locations, ranges, nil
+}
+
+// parseCellRange parses a range like "A1:C3" (or a single cell) into coordinates
+func parseCellRange(cellRange string) (startCol, startRow, endCol, endRow int, err error) {
+	parts := strings.Split(cellRange, ":")
+	if len(parts) > 2 {
+		return 0, 0, 0, 0, fmt.Errorf("expected format 'A1:C1'")
+	}
+	startCol, startRow, err = excelize.CellNameToCoordinates(parts[0])
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	endCol, endRow = startCol, startRow
+	if len(parts) == 2 {
+		endCol, endRow, err = excelize.CellNameToCoordinates(parts[1])
+		if err != nil {
+			return 0, 0, 0, 0, err
+		}
+	}
+	if startCol > endCol || startRow > endRow {
+		return 0, 0, 0, 0, fmt.Errorf("start cell must come before end cell")
+	}
+	return startCol, startRow, endCol, endRow, nil
+}
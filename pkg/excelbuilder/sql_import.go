@@ -0,0 +1,260 @@
+package excelbuilder
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default number formats of SQL columns
+const (
+	defaultSQLDateFormat     = "yyyy-mm-dd"
+	defaultSQLDateTimeFormat = "yyyy-mm-dd hh:mm:ss"
+)
+
+// sqlIntegerTypes are the database type names of integer columns
+var sqlIntegerTypes = []string{
+	"INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT", "MEDIUMINT",
+	"INT2", "INT4", "INT8", "SERIAL", "BIGSERIAL", "SMALLSERIAL",
+}
+
+// sqlColumn describes how the values of a result column are written
+type sqlColumn struct {
+	kind   string // integer, float, decimal, boolean, bit, date, datetime or text
+	format string // Number format, if any
+}
+
+// AddRowsFromSQL writes a header row with the column names of rows and a
+// row per result below it. Rows are read and written one at a time; rows
+// is read to the end but not closed. Values are converted by the database
+// type of their column: integers, floats and DECIMAL/NUMERIC columns become
+// numbers, with a format showing the scale of decimals; decimals that a
+// float64 cannot hold exactly, such as 12345678901234567.89, are written
+// as text so no digit is lost. BOOL and BIT(1) columns become booleans,
+// wider BIT columns integers, and DATE and TIMESTAMP/DATETIME columns dates
+// with a date format. NULL values leave their cell empty unless
+// options.NullValue is set.
+func (sb *SheetBuilder) AddRowsFromSQL(rows *sql.Rows, options SQLImportOptions) *SheetBuilder {
+	if sb.hasError {
+		return sb
+	}
+	if rows == nil {
+		sb.workbookBuilder.excelBuilder.AddError(fmt.Errorf("rows cannot be nil"))
+		sb.hasError = true
+		return sb
+	}
+
+	names, err := rows.Columns()
+	if err != nil {
+		sb.workbookBuilder.excelBuilder.AddError(fmt.Errorf("failed to read SQL columns: %w", err))
+		sb.hasError = true
+		return sb
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		sb.workbookBuilder.excelBuilder.AddError(fmt.Errorf("failed to read SQL column types: %w", err))
+		sb.hasError = true
+		return sb
+	}
+	columns := make([]sqlColumn, len(types))
+	for i, columnType := range types {
+		columns[i] = newSQLColumn(columnType, options)
+	}
+
+	if !options.SkipHeader {
+		headerStyle := StyleConfig{Font: FontConfig{Bold: true}}
+		if options.HeaderStyle != nil {
+			headerStyle = *options.HeaderStyle
+		}
+		headerRow := sb.AddRow()
+		for _, name := range names {
+			if title, ok := options.ColumnHeaders[name]; ok {
+				name = title
+			}
+			headerRow.AddCell(name).WithStyle(headerStyle)
+		}
+	}
+
+	values := make([]interface{}, len(names))
+	dest := make([]interface{}, len(names))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			sb.workbookBuilder.excelBuilder.AddError(fmt.Errorf("failed to scan SQL row: %w", err))
+			sb.hasError = true
+			return sb
+		}
+		row := sb.AddRow()
+		for i, value := range values {
+			value, format := columns[i].convert(value)
+			if value == nil {
+				if options.NullValue == "" {
+					row.AddCell(nil)
+					continue
+				}
+				value, format = options.NullValue, ""
+			}
+			cell := row.AddCell(value)
+			if format != "" {
+				cell.WithStyle(StyleConfig{NumberFormat: format})
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		sb.workbookBuilder.excelBuilder.AddError(fmt.Errorf("failed to read SQL rows: %w", err))
+		sb.hasError = true
+	}
+	return sb
+}
+
+// newSQLColumn returns how the values of a column of the given type are
+// written
+func newSQLColumn(columnType *sql.ColumnType, options SQLImportOptions) sqlColumn {
+	name := strings.ToUpper(strings.TrimSpace(columnType.DatabaseTypeName()))
+	name = strings.TrimPrefix(strings.TrimSuffix(name, " UNSIGNED"), "UNSIGNED ")
+
+	switch {
+	case containsString(sqlIntegerTypes, name):
+		return sqlColumn{kind: "integer"}
+	case name == "FLOAT", name == "FLOAT4", name == "FLOAT8", name == "REAL", strings.HasPrefix(name, "DOUBLE"):
+		return sqlColumn{kind: "float"}
+	case name == "DECIMAL", name == "NUMERIC", name == "NUMBER", strings.HasSuffix(name, "MONEY"):
+		format := options.DecimalFormat
+		if format == "" {
+			format = "#,##0"
+			if _, scale, ok := columnType.DecimalSize(); ok && scale > 0 {
+				format += "." + strings.Repeat("0", int(scale))
+			}
+		}
+		return sqlColumn{kind: "decimal", format: format}
+	case name == "BOOL", name == "BOOLEAN":
+		return sqlColumn{kind: "boolean"}
+	case name == "BIT", name == "VARBIT", name == "BIT VARYING":
+		return sqlColumn{kind: "bit"}
+	case name == "DATE":
+		return sqlColumn{kind: "date", format: firstNonEmpty(options.DateFormat, defaultSQLDateFormat)}
+	case strings.HasPrefix(name, "TIMESTAMP"), name == "DATETIME", name == "DATETIME2", name == "SMALLDATETIME":
+		return sqlColumn{kind: "datetime", format: firstNonEmpty(options.DateTimeFormat, defaultSQLDateTimeFormat)}
+	default:
+		return sqlColumn{kind: "text"}
+	}
+}
+
+// convert converts a value scanned from the column to a cell value and
+// returns it with its number format. Values that do not fit the column
+// are written as they are, and values of other columns by their Go type.
+func (c sqlColumn) convert(value interface{}) (interface{}, string) {
+	if raw, ok := value.([]byte); ok {
+		if c.kind == "bit" {
+			return bitValue(raw)
+		}
+		value = string(raw)
+	}
+	if value == nil {
+		return nil, ""
+	}
+
+	text, isText := value.(string)
+	switch c.kind {
+	case "integer":
+		if isText {
+			if number, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64); err == nil {
+				return number, c.format
+			}
+		}
+	case "float":
+		if isText {
+			if number, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+				return number, c.format
+			}
+		}
+		return value, c.format
+	case "decimal":
+		if isText {
+			if number, ok := exactFloat(strings.TrimSpace(text)); ok {
+				return number, c.format
+			}
+			return text, ""
+		}
+		return value, c.format
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+			return v, ""
+		case int64:
+			return v != 0, ""
+		case string:
+			if flag, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return flag, ""
+			}
+		}
+	case "date", "datetime":
+		if isText {
+			for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02"} {
+				if date, err := time.Parse(layout, strings.TrimSpace(text)); err == nil {
+					return date, c.format
+				}
+			}
+		}
+		if _, ok := value.(time.Time); ok {
+			return value, c.format
+		}
+		return value, ""
+	}
+
+	if _, ok := value.(time.Time); ok {
+		return value, defaultSQLDateTimeFormat
+	}
+	return value, ""
+}
+
+// bitValue converts a BIT value, which MySQL returns as big-endian bytes
+// and PostgreSQL as a string of 0s and 1s. A single bit is a boolean and
+// wider values are integers; values wider than 64 bits stay text.
+func bitValue(raw []byte) (interface{}, string) {
+	bits := new(big.Int)
+	if _, ok := bits.SetString(string(raw), 2); ok {
+		if len(raw) == 1 {
+			return raw[0] == '1', ""
+		}
+	} else {
+		bits.SetBytes(raw)
+		if len(raw) == 1 && bits.Int64() <= 1 {
+			return bits.Int64() == 1, ""
+		}
+	}
+	if !bits.IsInt64() {
+		return string(raw), ""
+	}
+	return bits.Int64(), ""
+}
+
+// exactFloat parses a decimal number and reports whether a float64 holds
+// it without losing digits
+func exactFloat(text string) (float64, bool) {
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, false
+	}
+	decimal, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return 0, false
+	}
+	shortest, _ := new(big.Rat).SetString(strconv.FormatFloat(number, 'g', -1, 64))
+	return number, decimal.Cmp(shortest) == 0
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
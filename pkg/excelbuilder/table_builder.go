@@ -0,0 +1,108 @@
+package excelbuilder
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// TableBuilder handles Excel table creation and configuration
+type TableBuilder struct {
+	sheetBuilder *SheetBuilder
+	file         *excelize.File
+	config       TableConfig
+}
+
+// NewTableBuilder creates a new TableBuilder for cellRange on the sheet
+func NewTableBuilder(sheetBuilder *SheetBuilder, cellRange string) *TableBuilder {
+	return &TableBuilder{
+		sheetBuilder: sheetBuilder,
+		file:         sheetBuilder.workbookBuilder.file,
+		config: TableConfig{
+			Range:          cellRange,
+			Style:          "TableStyleMedium2",
+			ShowRowStripes: true,
+			Slicers:        []SlicerConfig{},
+		},
+	}
+}
+
+// SetName sets the name of the table. Names must start with a letter or an
+// underscore and cannot contain spaces.
+func (tb *TableBuilder) SetName(name string) *TableBuilder {
+	tb.config.Name = name
+	return tb
+}
+
+// WithStyle sets the built-in table style, e.g. "TableStyleLight9"
+func (tb *TableBuilder) WithStyle(styleName string) *TableBuilder {
+	tb.config.Style = styleName
+	return tb
+}
+
+// ShowFirstColumn sets whether the first column is highlighted
+func (tb *TableBuilder) ShowFirstColumn(show bool) *TableBuilder {
+	tb.config.ShowFirstColumn = show
+	return tb
+}
+
+// ShowLastColumn sets whether the last column is highlighted
+func (tb *TableBuilder) ShowLastColumn(show bool) *TableBuilder {
+	tb.config.ShowLastColumn = show
+	return tb
+}
+
+// ShowRowStripes sets whether rows are banded
+func (tb *TableBuilder) ShowRowStripes(show bool) *TableBuilder {
+	tb.config.ShowRowStripes = show
+	return tb
+}
+
+// ShowColumnStripes sets whether columns are banded
+func (tb *TableBuilder) ShowColumnStripes(show bool) *TableBuilder {
+	tb.config.ShowColumnStripes = show
+	return tb
+}
+
+// AddSlicer adds a slicer for the table column field at cell. The slicer is
+// placed on the table's sheet unless opts.Sheet is set.
+func (tb *TableBuilder) AddSlicer(field, cell string, opts SlicerOptions) *TableBuilder {
+	tb.config.Slicers = append(tb.config.Slicers, SlicerConfig{
+		Field:   field,
+		Cell:    cell,
+		Options: opts,
+	})
+	return tb
+}
+
+// GetConfig returns the current table configuration
+func (tb *TableBuilder) GetConfig() TableConfig {
+	return tb.config
+}
+
+// Build creates the table and its slicers
+func (tb *TableBuilder) Build() error {
+	if tb.config.Name == "" {
+		tb.config.Name = fmt.Sprintf("Table%d", countParts(tb.file, "xl/tables/table")+1)
+	}
+
+	err := tb.file.AddTable(tb.sheetBuilder.sheetName, &excelize.Table{
+		Range:             tb.config.Range,
+		Name:              tb.config.Name,
+		StyleName:         tb.config.Style,
+		ShowFirstColumn:   tb.config.ShowFirstColumn,
+		ShowLastColumn:    tb.config.ShowLastColumn,
+		ShowRowStripes:    &tb.config.ShowRowStripes,
+		ShowColumnStripes: tb.config.ShowColumnStripes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create table %s: %w", tb.config.Name, err)
+	}
+
+	for _, slicer := range tb.config.Slicers {
+		if err := addSlicer(tb.file, tb.sheetBuilder.sheetName, tb.config.Name, slicer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package excelbuilder
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RecordError reports a record of RenderMany that could not be rendered
+type RecordError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+// Error implements the error interface
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d (%s): %v", e.Index, e.Name, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// RenderManyError lists the records RenderMany skipped. The archive holds
+// the workbooks of all other records.
+type RenderManyError struct {
+	Records []*RecordError
+}
+
+// Error implements the error interface
+func (e *RenderManyError) Error() string {
+	messages := make([]string, len(e.Records))
+	for i, record := range e.Records {
+		messages[i] = record.Error()
+	}
+	return fmt.Sprintf("%d records failed to render: %s", len(e.Records), strings.Join(messages, "; "))
+}
+
+// WithConcurrency sets how many workbooks RenderMany renders at the same
+// time. It defaults to the number of CPUs.
+func (tb *TemplateBuilder) WithConcurrency(workers int) *TemplateBuilder {
+	tb.concurrency = workers
+	return tb
+}
+
+// RenderMany renders the template once per record and writes the
+// workbooks to w as a zip archive, in record order. nameFunc names the
+// entry of each record; names are cleaned with path.Clean and ".xlsx" is
+// appended when the name has no extension. The template itself is left
+// unprocessed.
+//
+// Records whose name is empty, already used, absolute or outside the
+// archive root ("..") or whose rendering reports
+// an error, are left out of the archive and returned in a
+// *RenderManyError once the archive is complete. Any other error aborts
+// the archive.
+func (tb *TemplateBuilder) RenderMany(records []map[string]interface{}, nameFunc func(index int, record map[string]interface{}) string, w io.Writer) error {
+	buffer, err := tb.file.WriteToBuffer()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot template: %w", err)
+	}
+	template := buffer.Bytes()
+
+	workers := tb.concurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var failed []*RecordError
+	names := make([]string, len(records))
+	used := make(map[string]bool)
+	for i, record := range records {
+		name := path.Clean(nameFunc(i, record))
+		if name == "." {
+			name = ""
+		}
+		if name != "" && path.Ext(name) == "" {
+			name += ".xlsx"
+		}
+		switch {
+		case name == "":
+			failed = append(failed, &RecordError{Index: i, Err: fmt.Errorf("workbook name is empty")})
+			continue
+		case strings.HasPrefix(name, "/"), name == "..", strings.HasPrefix(name, "../"):
+			failed = append(failed, &RecordError{Index: i, Name: name, Err: fmt.Errorf("workbook name is outside the archive")})
+			continue
+		case used[name]:
+			failed = append(failed, &RecordError{Index: i, Name: name, Err: fmt.Errorf("workbook name is already used")})
+			continue
+		}
+		used[name] = true
+		names[i] = name
+	}
+
+	type renderResult struct {
+		content []byte
+		err     error
+	}
+	results := make([]chan renderResult, len(records))
+	for i := range results {
+		results[i] = make(chan renderResult, 1)
+	}
+
+	// A slot is taken when a record starts rendering and released once its
+	// workbook is written, bounding the workbooks held in memory
+	slots := make(chan struct{}, workers)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for i, record := range records {
+			if names[i] == "" {
+				continue
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-stop:
+				return
+			}
+			go func(i int, record map[string]interface{}) {
+				content, err := tb.renderRecord(template, record)
+				results[i] <- renderResult{content: content, err: err}
+			}(i, record)
+		}
+	}()
+
+	archive := zip.NewWriter(w)
+	for i := range records {
+		if names[i] == "" {
+			continue
+		}
+		result := <-results[i]
+		if result.err != nil {
+			failed = append(failed, &RecordError{Index: i, Name: names[i], Err: result.err})
+			<-slots
+			continue
+		}
+
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     names[i],
+			Method:   zip.Store, // Workbooks are already compressed
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add %s to archive: %w", names[i], err)
+		}
+		if _, err := entry.Write(result.content); err != nil {
+			return fmt.Errorf("failed to write %s to archive: %w", names[i], err)
+		}
+		<-slots
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	if len(failed) > 0 {
+		sort.Slice(failed, func(i, j int) bool { return failed[i].Index < failed[j].Index })
+		return &RenderManyError{Records: failed}
+	}
+	return nil
+}
+
+// renderRecord processes a fresh copy of the template with one record
+func (tb *TemplateBuilder) renderRecord(template []byte, record map[string]interface{}) ([]byte, error) {
+	clone, err := LoadTemplate(bytes.NewReader(template))
+	if err != nil {
+		return nil, err
+	}
+	defer clone.file.Close()
+
+	clone.filters = tb.filters
+	clone.ProcessTemplate(record)
+	if errs := clone.Errors(); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	buffer, err := clone.file.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
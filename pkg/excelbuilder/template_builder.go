@@ -2,12 +2,16 @@ package excelbuilder
 
 import (
 	"fmt"
+	"io"
 	"regexp"
 	"strings"
 
 	"github.com/xuri/excelize/v2"
 )
 
+// placeholderPattern matches a {{key}} placeholder
+var placeholderPattern = regexp.MustCompile(`\{\{([^}]+)\}\}`)
+
 // TemplateBuilder provides functionality for creating and processing Excel templates
 // with placeholder substitution and dynamic content generation.
 type TemplateBuilder struct {
@@ -17,6 +21,8 @@ type TemplateBuilder struct {
 	currentRow   int
 	currentCol   int
 	templateData map[string]interface{}
+	filters      map[string]TemplateFilter
+	concurrency  int // Workbooks rendered at once by RenderMany
 }
 
 // TemplateCellBuilder handles individual cell operations in template building
@@ -42,7 +48,7 @@ type TemplateSheetBuilder struct {
 func NewTemplateBuilder() *TemplateBuilder {
 	file := excelize.NewFile()
 	return &TemplateBuilder{
-		excelBuilder: New(),
+		excelBuilder: New().WithErrorCollection(true),
 		file:         file,
 		currentRow:   1,
 		currentCol:   1,
@@ -58,7 +64,24 @@ func LoadExistingFile(filePath string) (*TemplateBuilder, error) {
 	}
 
 	return &TemplateBuilder{
-		excelBuilder: New(),
+		excelBuilder: New().WithErrorCollection(true),
+		file:         file,
+		currentRow:   1,
+		currentCol:   1,
+		templateData: make(map[string]interface{}),
+	}, nil
+}
+
+// LoadTemplate loads an Excel template from a reader, such as a file of an
+// embed.FS, for template processing
+func LoadTemplate(r io.Reader) (*TemplateBuilder, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open template: %w", err)
+	}
+
+	return &TemplateBuilder{
+		excelBuilder: New().WithErrorCollection(true),
 		file:         file,
 		currentRow:   1,
 		currentCol:   1,
@@ -135,17 +158,40 @@ func (tsb *TemplateSheetBuilder) Done() *TemplateBuilder {
 	return tsb.templateBuilder
 }
 
-// ProcessTemplate processes the template with the provided data
+// ProcessTemplate processes the template with the provided data. Rows
+// enclosed in {{#each key}} ... {{/each}} are repeated for every element of
+// data[key] before the remaining placeholders are substituted. Besides
+// cells, placeholders are replaced in headers and footers, comments, data
+// validation messages, defined names, chart titles and sheet names.
+// Placeholders whose expression or filter fails are left as they are and
+// reported by Errors.
 func (tb *TemplateBuilder) ProcessTemplate(data map[string]interface{}) *TemplateBuilder {
 	tb.templateData = data
-	
+
 	// Get all sheet names
 	sheetNames := tb.file.GetSheetList()
-	
+
 	for _, sheetName := range sheetNames {
+		if err := tb.expandLoops(sheetName, data); err != nil {
+			tb.excelBuilder.AddError(err)
+			continue
+		}
 		tb.processSheet(sheetName, data)
+		if err := tb.processSheetParts(sheetName, data); err != nil {
+			tb.excelBuilder.AddError(err)
+		}
+	}
+
+	if err := tb.processDefinedNames(data); err != nil {
+		tb.excelBuilder.AddError(err)
+	}
+	if err := tb.processChartTitles(data); err != nil {
+		tb.excelBuilder.AddError(fmt.Errorf("chart titles: %w", err))
 	}
-	
+
+	// Rename sheets last so the steps above can still find them
+	tb.renameSheets(data)
+
 	return tb
 }
 
@@ -155,37 +201,85 @@ func (tb *TemplateBuilder) processSheet(sheetName string, data map[string]interf
 	if err != nil {
 		return
 	}
-	
+
 	for rowIndex, row := range rows {
 		for colIndex, cellValue := range row {
-			if cellValue != "" {
-				processedValue := tb.replacePlaceholders(cellValue, data)
+			// Only rewrite cells with placeholders so that numbers and
+			// formulas keep their type
+			if strings.Contains(cellValue, "{{") {
 				cellRef, _ := excelize.CoordinatesToCellName(colIndex+1, rowIndex+1)
-				tb.file.SetCellValue(sheetName, cellRef, processedValue)
+				if err := tb.fillCell(sheetName, cellRef, cellValue, data); err != nil {
+					tb.excelBuilder.AddError(err)
+				}
 			}
 		}
 	}
 }
 
-// replacePlaceholders replaces template placeholders with actual data
+// fillCell writes the substituted value of a template cell, or the image
+// of an {{image:key}} placeholder
+func (tb *TemplateBuilder) fillCell(sheetName, cellRef, cellValue string, data map[string]interface{}) error {
+	if match := imagePlaceholderPattern.FindStringSubmatch(cellValue); match != nil {
+		return tb.insertImage(sheetName, cellRef, strings.TrimSpace(match[1]), data)
+	}
+	return tb.file.SetCellValue(sheetName, cellRef, tb.substituteCell(cellValue, data))
+}
+
+// substituteCell returns the new value of a cell. When the cell holds a
+// single placeholder and nothing else, the data value is returned as is so
+// numbers, booleans and dates are written with their native type and keep
+// the cell's number format. Otherwise the placeholders are replaced as text.
+func (tb *TemplateBuilder) substituteCell(cellValue string, data map[string]interface{}) interface{} {
+	if match := placeholderPattern.FindStringSubmatchIndex(cellValue); match != nil && match[0] == 0 && match[1] == len(cellValue) {
+		value, found, err := tb.evaluatePlaceholder(cellValue[match[2]:match[3]], data)
+		if err != nil {
+			tb.excelBuilder.AddError(err)
+			return cellValue
+		}
+		if found {
+			return value
+		}
+	}
+	return tb.replacePlaceholders(cellValue, data)
+}
+
+// replacePlaceholders replaces template placeholders with actual data.
+// Placeholders may use dotted paths, indexes and filters, e.g.
+// {{customer.address.city}}, {{items[0].sku}} or {{name | upper}}.
 func (tb *TemplateBuilder) replacePlaceholders(template string, data map[string]interface{}) string {
-	// Simple placeholder replacement using {{key}} syntax
-	re := regexp.MustCompile(`\{\{([^}]+)\}\}`)
-	
-	return re.ReplaceAllStringFunc(template, func(match string) string {
-		// Extract the key from {{key}}
-		key := strings.Trim(match, "{}")
-		key = strings.TrimSpace(key)
-		
-		if value, exists := data[key]; exists {
+	return substitutePlaceholders(template, data, tb.filters, tb.excelBuilder.AddError)
+}
+
+// substitutePlaceholders replaces the placeholders of template with their
+// values, evaluated with filters and the built-in filters. Placeholders
+// that fail pass their error to addError and, like placeholders without
+// data, are left as they are.
+func substitutePlaceholders(template string, data map[string]interface{}, filters map[string]TemplateFilter, addError func(error)) string {
+	return placeholderPattern.ReplaceAllStringFunc(template, func(match string) string {
+		// Extract the expression from {{expression}}
+		expression := match[2 : len(match)-2]
+
+		value, found, err := evaluateExpression(expression, data, filters)
+		if err != nil {
+			addError(err)
+			return match
+		}
+		if found {
 			return fmt.Sprintf("%v", value)
 		}
-		
+
 		// Return original placeholder if no data found
 		return match
 	})
 }
 
+// Errors returns the errors met while processing the template, such as an
+// unclosed {{#each}} block, a failing filter or an image that cannot be
+// inserted. The placeholders involved are left as they are.
+func (tb *TemplateBuilder) Errors() []error {
+	return tb.excelBuilder.GetCollectedErrors()
+}
+
 // Build returns the final Excel file
 func (tb *TemplateBuilder) Build() *excelize.File {
 	return tb.file
@@ -0,0 +1,345 @@
+package excelbuilder
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TemplateFilter transforms a placeholder value, e.g. {{name | upper}}.
+// args holds the filter arguments written after the filter name.
+type TemplateFilter func(value interface{}, args ...string) (interface{}, error)
+
+// currencySymbols maps ISO currency codes to their symbol and decimal places
+var currencySymbols = map[string]struct {
+	symbol   string
+	decimals int
+}{
+	"USD": {"$", 2},
+	"EUR": {"€", 2},
+	"GBP": {"£", 2},
+	"JPY": {"¥", 0},
+	"CNY": {"¥", 2},
+	"INR": {"₹", 2},
+	"VND": {"₫", 0},
+}
+
+// builtinFilters are available to every template
+var builtinFilters = map[string]TemplateFilter{
+	"upper": func(value interface{}, args ...string) (interface{}, error) {
+		return strings.ToUpper(fmt.Sprintf("%v", value)), nil
+	},
+	"lower": func(value interface{}, args ...string) (interface{}, error) {
+		return strings.ToLower(fmt.Sprintf("%v", value)), nil
+	},
+	"trim": func(value interface{}, args ...string) (interface{}, error) {
+		return strings.TrimSpace(fmt.Sprintf("%v", value)), nil
+	},
+	"default": func(value interface{}, args ...string) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("default expects 1 argument, got %d", len(args))
+		}
+		if value == nil || fmt.Sprintf("%v", value) == "" {
+			return args[0], nil
+		}
+		return value, nil
+	},
+	"format":   formatFilter,
+	"currency": currencyFilter,
+}
+
+// RegisterFilter registers a filter usable in placeholders as
+// {{key | name arg...}}. Registered filters take precedence over the
+// built-in upper, lower, trim, default, format and currency filters.
+func (tb *TemplateBuilder) RegisterFilter(name string, filter TemplateFilter) *TemplateBuilder {
+	if tb.filters == nil {
+		tb.filters = make(map[string]TemplateFilter)
+	}
+	tb.filters[name] = filter
+	return tb
+}
+
+// evaluatePlaceholder resolves a placeholder expression against data with
+// the filters registered on the builder
+func (tb *TemplateBuilder) evaluatePlaceholder(expression string, data map[string]interface{}) (interface{}, bool, error) {
+	return evaluateExpression(expression, data, tb.filters)
+}
+
+// evaluateExpression resolves a placeholder expression such as
+// `customer.address.city` or `total | currency "USD"` against data. Filters
+// are looked up in filters before the built-in ones. It reports false when
+// the path does not exist.
+func evaluateExpression(expression string, data map[string]interface{}, filters map[string]TemplateFilter) (interface{}, bool, error) {
+	parts, err := splitPipes(expression)
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, found := resolvePath(data, strings.TrimSpace(parts[0]))
+	if !found {
+		// A leading default filter also stands in for a missing key
+		if len(parts) < 2 || !isDefaultFilter(parts[1]) {
+			return nil, false, nil
+		}
+	}
+
+	for _, part := range parts[1:] {
+		words, err := splitFilterArgs(part)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(words) == 0 {
+			return nil, false, fmt.Errorf("empty filter in '%s'", expression)
+		}
+		filter, ok := filters[words[0]]
+		if !ok {
+			filter, ok = builtinFilters[words[0]]
+		}
+		if !ok {
+			return nil, false, fmt.Errorf("unknown filter '%s' in '%s'", words[0], expression)
+		}
+		if value, err = filter(value, words[1:]...); err != nil {
+			return nil, false, fmt.Errorf("filter '%s' in '%s': %w", words[0], expression, err)
+		}
+	}
+	return value, true, nil
+}
+
+// isDefaultFilter reports whether a filter call uses the default filter
+func isDefaultFilter(call string) bool {
+	words, err := splitFilterArgs(call)
+	return err == nil && len(words) > 0 && words[0] == "default"
+}
+
+// splitPipes splits an expression on the pipes that are not quoted
+func splitPipes(expression string) ([]string, error) {
+	var parts []string
+	inQuotes, start := false, 0
+	for i, ch := range expression {
+		switch {
+		case ch == '"':
+			inQuotes = !inQuotes
+		case ch == '|' && !inQuotes:
+			parts = append(parts, expression[start:i])
+			start = i + 1
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in '%s'", expression)
+	}
+	return append(parts, expression[start:]), nil
+}
+
+// splitFilterArgs splits a filter call into its name and arguments.
+// Arguments may be double-quoted to include spaces.
+func splitFilterArgs(call string) ([]string, error) {
+	var words []string
+	runes := []rune(strings.TrimSpace(call))
+	for i := 0; i < len(runes); {
+		switch {
+		case unicode.IsSpace(runes[i]):
+			i++
+		case runes[i] == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated quote in '%s'", call)
+			}
+			words = append(words, string(runes[i+1:end]))
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+			words = append(words, string(runes[i:end]))
+			i = end
+		}
+	}
+	return words, nil
+}
+
+// resolvePath looks up a dotted path with optional indexes, such as
+// customer.address.city or items[0].sku, in nested maps, structs, slices
+// and pointers. Map keys and struct fields match exactly, then
+// case-insensitively; struct fields also match their json tag.
+func resolvePath(data map[string]interface{}, path string) (interface{}, bool) {
+	if value, exists := data[path]; exists {
+		return value, true
+	}
+
+	var current interface{} = data
+	for _, segment := range strings.Split(path, ".") {
+		name, indexes, ok := parsePathSegment(segment)
+		if !ok {
+			return nil, false
+		}
+		if name != "" {
+			if current, ok = lookupField(current, name); !ok {
+				return nil, false
+			}
+		}
+		for _, index := range indexes {
+			if current, ok = lookupIndex(current, index); !ok {
+				return nil, false
+			}
+		}
+	}
+	return current, true
+}
+
+// parsePathSegment splits "items[0][1]" into "items" and [0 1]
+func parsePathSegment(segment string) (string, []int, bool) {
+	open := strings.IndexByte(segment, '[')
+	if open == -1 {
+		return segment, nil, segment != ""
+	}
+
+	name, rest := segment[:open], segment[open:]
+	var indexes []int
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end == -1 {
+			return "", nil, false
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(rest[1:end]))
+		if err != nil {
+			return "", nil, false
+		}
+		indexes = append(indexes, index)
+		rest = rest[end+1:]
+	}
+	return name, indexes, true
+}
+
+// lookupField returns a map entry or struct field of value
+func lookupField(value interface{}, name string) (interface{}, bool) {
+	rv := indirectValue(reflect.ValueOf(value))
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		if entry := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key())); entry.IsValid() {
+			return entry.Interface(), true
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			if strings.EqualFold(iter.Key().String(), name) {
+				return iter.Value().Interface(), true
+			}
+		}
+	case reflect.Struct:
+		if field, ok := rv.Type().FieldByName(name); ok && field.IsExported() {
+			if value, err := rv.FieldByIndexErr(field.Index); err == nil {
+				return value.Interface(), true
+			}
+		}
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			tag := strings.Split(field.Tag.Get("json"), ",")[0]
+			if tag == name || strings.EqualFold(field.Name, name) {
+				return rv.Field(i).Interface(), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// lookupIndex returns the element at index of a slice or array
+func lookupIndex(value interface{}, index int) (interface{}, bool) {
+	rv := indirectValue(reflect.ValueOf(value))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	if index < 0 || index >= rv.Len() {
+		return nil, false
+	}
+	return rv.Index(index).Interface(), true
+}
+
+// indirectValue dereferences pointers and interfaces
+func indirectValue(rv reflect.Value) reflect.Value {
+	for (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// formatFilter formats dates with a Go layout and other values with a fmt
+// verb, e.g. {{date | format "2006-01-02"}} or {{ratio | format "%.1f"}}.
+// Text dates are parsed before they are formatted; an argument without a
+// verb only formats dates.
+func formatFilter(value interface{}, args ...string) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("format expects 1 argument, got %d", len(args))
+	}
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(args[0]), nil
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		return v.Format(args[0]), nil
+	case string:
+		for _, layout := range dateLayouts {
+			if date, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return date.Format(args[0]), nil
+			}
+		}
+	}
+	if !strings.Contains(args[0], "%") {
+		return nil, fmt.Errorf("cannot format %v with date layout '%s'", value, args[0])
+	}
+	return fmt.Sprintf(args[0], value), nil
+}
+
+// currencyFilter formats a number as an amount of the given ISO currency,
+// e.g. {{total | currency "USD"}} gives "$1,234.50"
+func currencyFilter(value interface{}, args ...string) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("currency expects 1 argument, got %d", len(args))
+	}
+	amount, ok := toFloat64(value)
+	if !ok {
+		return nil, fmt.Errorf("cannot format %v as currency", value)
+	}
+
+	code := strings.ToUpper(args[0])
+	currency, known := currencySymbols[code]
+	if !known {
+		currency.symbol, currency.decimals = code+" ", 2
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return sign + currency.symbol + groupThousands(strconv.FormatFloat(amount, 'f', currency.decimals, 64)), nil
+}
+
+// groupThousands inserts thousands separators into a formatted number
+func groupThousands(number string) string {
+	integer, fraction := number, ""
+	if dot := strings.IndexByte(number, '.'); dot != -1 {
+		integer, fraction = number[:dot], number[dot:]
+	}
+
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return grouped.String() + fraction
+}
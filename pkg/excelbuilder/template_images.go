@@ -0,0 +1,75 @@
+package excelbuilder
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// imagePlaceholderPattern matches a cell holding only an {{image:key}} placeholder
+var imagePlaceholderPattern = regexp.MustCompile(`^\{\{\s*image:([^}]+)\}\}$`)
+
+// imageExtensions maps detected image content types to file extensions
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/bmp":  ".bmp",
+}
+
+// insertImage replaces an {{image:key}} placeholder with the image bound to
+// key, given as bytes or a file path. The image is scaled to fit the cell,
+// or its merged range, keeping its aspect ratio. The placeholder is kept
+// when key is not in data or the image cannot be inserted; the error is
+// then reported through Errors.
+func (tb *TemplateBuilder) insertImage(sheetName, cellRef, key string, data map[string]interface{}) error {
+	value, exists := resolvePath(data, key)
+	if !exists {
+		return nil
+	}
+
+	var (
+		content   []byte
+		extension string
+	)
+	switch v := value.(type) {
+	case []byte:
+		content = v
+		extension = imageExtensions[http.DetectContentType(v)]
+		if extension == "" {
+			return fmt.Errorf("image %s in %s!%s: unsupported image format", key, sheetName, cellRef)
+		}
+	case string:
+		var err error
+		if content, err = os.ReadFile(v); err != nil {
+			return fmt.Errorf("image %s in %s!%s: %w", key, sheetName, cellRef, err)
+		}
+		extension = strings.ToLower(filepath.Ext(v))
+	case nil:
+		return tb.file.SetCellValue(sheetName, cellRef, nil)
+	default:
+		return fmt.Errorf("image %s in %s!%s: expected []byte or file path, got %T", key, sheetName, cellRef, value)
+	}
+
+	// Clear the placeholder so it does not show behind the image
+	if err := tb.file.SetCellValue(sheetName, cellRef, nil); err != nil {
+		return err
+	}
+	err := tb.file.AddPictureFromBytes(sheetName, cellRef, &excelize.Picture{
+		Extension: extension,
+		File:      content,
+		Format: &excelize.GraphicOptions{
+			AltText: key,
+			AutoFit: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("image %s in %s!%s: %w", key, sheetName, cellRef, err)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package excelbuilder
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	// eachStartPattern matches the opening directive of a row loop
+	eachStartPattern = regexp.MustCompile(`\{\{\s*#each\s+([^}\s]+)\s*\}\}`)
+	// eachEndPattern matches the closing directive of a row loop
+	eachEndPattern = regexp.MustCompile(`\{\{\s*/each\s*\}\}`)
+	// rangeRefPattern matches a range reference such as D5:D5 or $D$5:$F$9
+	rangeRefPattern = regexp.MustCompile(`(^|[^A-Za-z0-9_!.])(\$?[A-Z]{1,3}\$?)(\d+):(\$?[A-Z]{1,3}\$?)(\d+)`)
+)
+
+// templateLoop is a block of template rows repeated for every item of a list
+type templateLoop struct {
+	key      string
+	startRow int
+	endRow   int
+}
+
+// expandLoops repeats the rows between {{#each key}} and {{/each}} once per
+// element of data[key]. The markers may be placed in any cell of the first
+// and last row of the block; both can be on the same row. Inside the block,
+// placeholders resolve against the element first, then against data.
+// {{this}} is the element itself and {{@index}} its zero-based position.
+// Blocks whose key is not in data are left for a later pass.
+func (tb *TemplateBuilder) expandLoops(sheetName string, data map[string]interface{}) error {
+	fromRow := 1
+	for {
+		loop, found, err := tb.findLoop(sheetName, fromRow)
+		if err != nil || !found {
+			return err
+		}
+
+		items, exists, err := loopItems(data, loop.key)
+		if err != nil {
+			return fmt.Errorf("sheet %s row %d: %w", sheetName, loop.startRow, err)
+		}
+		if !exists {
+			fromRow = loop.endRow + 1
+			continue
+		}
+		fromRow = loop.startRow
+		if err := tb.expandLoop(sheetName, loop, items, data); err != nil {
+			return fmt.Errorf("sheet %s row %d: %w", sheetName, loop.startRow, err)
+		}
+	}
+}
+
+// findLoop returns the first loop block of the sheet starting at fromRow or below
+func (tb *TemplateBuilder) findLoop(sheetName string, fromRow int) (templateLoop, bool, error) {
+	rows, err := tb.file.GetRows(sheetName)
+	if err != nil {
+		return templateLoop{}, false, err
+	}
+
+	loop := templateLoop{}
+	for rowIndex, row := range rows {
+		if rowIndex+1 < fromRow {
+			continue
+		}
+		for _, cellValue := range row {
+			if loop.key == "" {
+				if match := eachStartPattern.FindStringSubmatch(cellValue); match != nil {
+					loop.key = match[1]
+					loop.startRow = rowIndex + 1
+				}
+			}
+		}
+		if loop.key == "" {
+			continue
+		}
+		for _, cellValue := range row {
+			if eachEndPattern.MatchString(cellValue) {
+				loop.endRow = rowIndex + 1
+				return loop, true, nil
+			}
+		}
+	}
+
+	if loop.key != "" {
+		return loop, false, fmt.Errorf("sheet %s row %d: {{#each %s}} has no matching {{/each}}", sheetName, loop.startRow, loop.key)
+	}
+	return loop, false, nil
+}
+
+// expandLoop duplicates the block rows once per item and fills them in
+func (tb *TemplateBuilder) expandLoop(sheetName string, loop templateLoop, items []interface{}, data map[string]interface{}) error {
+	height := loop.endRow - loop.startRow + 1
+
+	if len(items) == 0 {
+		for i := 0; i < height; i++ {
+			if err := tb.file.RemoveRow(sheetName, loop.startRow); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Copies are inserted below the block, so the block rows themselves
+	// never move while duplicating
+	for i := 1; i < len(items); i++ {
+		for j := 0; j < height; j++ {
+			if err := tb.file.DuplicateRowTo(sheetName, loop.startRow+j, loop.startRow+i*height+j); err != nil {
+				return err
+			}
+		}
+	}
+	if len(items) > 1 {
+		if err := tb.extendBlockRanges(sheetName, loop, (len(items)-1)*height); err != nil {
+			return err
+		}
+	}
+
+	lastCol, _, err := tb.usedBounds(sheetName)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		scope := loopScope(data, item, i)
+		first := loop.startRow + i*height
+		for row := first; row < first+height; row++ {
+			if err := tb.fillLoopRow(sheetName, row, lastCol, scope); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fillLoopRow strips the loop markers from a row and substitutes its placeholders
+func (tb *TemplateBuilder) fillLoopRow(sheetName string, row, lastCol int, scope map[string]interface{}) error {
+	for col := 1; col <= lastCol; col++ {
+		cellRef, _ := excelize.CoordinatesToCellName(col, row)
+		cellValue, err := tb.file.GetCellValue(sheetName, cellRef)
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(cellValue, "{{") {
+			continue
+		}
+		cellValue = eachStartPattern.ReplaceAllString(cellValue, "")
+		cellValue = eachEndPattern.ReplaceAllString(cellValue, "")
+		if err := tb.fillCell(sheetName, cellRef, cellValue, scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extendBlockRanges grows formula ranges that end on the last block row so
+// they cover the inserted rows. excelize already grows ranges that extend
+// past the block, but leaves ranges such as SUM(D5:D5) untouched.
+func (tb *TemplateBuilder) extendBlockRanges(sheetName string, loop templateLoop, inserted int) error {
+	endCol, endRow, err := tb.usedBounds(sheetName)
+	if err != nil {
+		return err
+	}
+
+	blockEnd := loop.endRow + inserted
+	for row := 1; row <= endRow; row++ {
+		if row >= loop.startRow && row <= blockEnd {
+			continue
+		}
+		for col := 1; col <= endCol; col++ {
+			cellRef, _ := excelize.CoordinatesToCellName(col, row)
+			formula, err := tb.file.GetCellFormula(sheetName, cellRef)
+			if err != nil {
+				return err
+			}
+			if formula == "" {
+				continue
+			}
+			adjusted := extendRangeRefs(formula, loop.endRow, inserted)
+			if adjusted != formula {
+				if err := tb.file.SetCellFormula(sheetName, cellRef, adjusted); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// usedBounds returns the last column and row holding a value or formula
+func (tb *TemplateBuilder) usedBounds(sheetName string) (lastCol, lastRow int, err error) {
+	rows, err := tb.file.GetRows(sheetName)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, row := range rows {
+		if len(row) > lastCol {
+			lastCol = len(row)
+		}
+	}
+	return lastCol, len(rows), nil
+}
+
+// extendRangeRefs moves the end of every range that ends exactly on endRow
+// down by inserted rows
+func extendRangeRefs(formula string, endRow, inserted int) string {
+	return rangeRefPattern.ReplaceAllStringFunc(formula, func(ref string) string {
+		match := rangeRefPattern.FindStringSubmatch(ref)
+		first, _ := strconv.Atoi(match[3])
+		last, _ := strconv.Atoi(match[5])
+		if last != endRow || first > last {
+			return ref
+		}
+		return fmt.Sprintf("%s%s%d:%s%d", match[1], match[2], first, match[4], last+inserted)
+	})
+}
+
+// loopItems returns the elements of the list bound to key and whether key exists
+func loopItems(data map[string]interface{}, key string) ([]interface{}, bool, error) {
+	value, exists := resolvePath(data, key)
+	if !exists || value == nil {
+		return nil, exists, nil
+	}
+
+	items, ok := sliceItems(value)
+	if !ok {
+		return nil, true, fmt.Errorf("{{#each %s}} requires a slice, got %T", key, value)
+	}
+	return items, true, nil
+}
+
+// sliceItems returns the elements of a slice or array
+func sliceItems(value interface{}) ([]interface{}, bool) {
+	rv := indirectValue(reflect.ValueOf(value))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+// loopScope returns the placeholder values visible inside a loop block:
+// the outer data, overridden by the fields of the item
+func loopScope(data map[string]interface{}, item interface{}, index int) map[string]interface{} {
+	scope := make(map[string]interface{}, len(data)+2)
+	for key, value := range data {
+		scope[key] = value
+	}
+
+	rv := reflect.ValueOf(item)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			iter := rv.MapRange()
+			for iter.Next() {
+				scope[iter.Key().String()] = iter.Value().Interface()
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < rv.NumField(); i++ {
+			if field := rv.Type().Field(i); field.IsExported() {
+				scope[field.Name] = rv.Field(i).Interface()
+			}
+		}
+	}
+
+	scope["this"] = item
+	scope["@index"] = index
+	return scope
+}
@@ -0,0 +1,334 @@
+package excelbuilder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// unquotedSheetName matches sheet names that need no quotes in references
+var unquotedSheetName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// processSheetParts substitutes the placeholders of a sheet outside its
+// cells: header and footer, comments and data validation messages
+func (tb *TemplateBuilder) processSheetParts(sheetName string, data map[string]interface{}) error {
+	if err := tb.processHeaderFooter(sheetName, data); err != nil {
+		return fmt.Errorf("sheet %s header and footer: %w", sheetName, err)
+	}
+	if err := tb.processComments(sheetName, data); err != nil {
+		return fmt.Errorf("sheet %s comments: %w", sheetName, err)
+	}
+	if err := tb.processDataValidations(sheetName, data); err != nil {
+		return fmt.Errorf("sheet %s data validations: %w", sheetName, err)
+	}
+	return nil
+}
+
+// processHeaderFooter substitutes the placeholders of the header and footer
+func (tb *TemplateBuilder) processHeaderFooter(sheetName string, data map[string]interface{}) error {
+	opts, err := tb.file.GetHeaderFooter(sheetName)
+	if err != nil || opts == nil {
+		return err
+	}
+
+	changed := false
+	for _, text := range []*string{
+		&opts.OddHeader, &opts.OddFooter,
+		&opts.EvenHeader, &opts.EvenFooter,
+		&opts.FirstHeader, &opts.FirstFooter,
+	} {
+		changed = tb.substituteText(text, data) || changed
+	}
+	if !changed {
+		return nil
+	}
+	return tb.file.SetHeaderFooter(sheetName, opts)
+}
+
+// processComments substitutes the placeholders of cell comments
+func (tb *TemplateBuilder) processComments(sheetName string, data map[string]interface{}) error {
+	comments, err := tb.file.GetComments(sheetName)
+	if err != nil {
+		return err
+	}
+
+	for _, comment := range comments {
+		changed := tb.substituteText(&comment.Text, data)
+		for i := range comment.Paragraph {
+			changed = tb.substituteText(&comment.Paragraph[i].Text, data) || changed
+		}
+		if !changed {
+			continue
+		}
+		if err := tb.file.DeleteComment(sheetName, comment.Cell); err != nil {
+			return err
+		}
+		if err := tb.file.AddComment(sheetName, comment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processDataValidations substitutes the placeholders of the input and
+// error messages of data validations
+func (tb *TemplateBuilder) processDataValidations(sheetName string, data map[string]interface{}) error {
+	validations, err := tb.file.GetDataValidations(sheetName)
+	if err != nil {
+		return err
+	}
+
+	for _, dv := range validations {
+		changed := false
+		for _, text := range []*string{dv.Prompt, dv.PromptTitle, dv.Error, dv.ErrorTitle} {
+			if text != nil {
+				changed = tb.substituteText(text, data) || changed
+			}
+		}
+		if !changed {
+			continue
+		}
+		if err := tb.file.DeleteDataValidation(sheetName, dv.Sqref); err != nil {
+			return err
+		}
+		if err := tb.file.AddDataValidation(sheetName, dv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processDefinedNames substitutes the placeholders of defined name values
+// and comments, e.g. a name referring to ="{{company}}"
+func (tb *TemplateBuilder) processDefinedNames(data map[string]interface{}) error {
+	for _, name := range tb.file.GetDefinedName() {
+		updated := name
+		changed := tb.substituteText(&updated.RefersTo, data)
+		changed = tb.substituteText(&updated.Comment, data) || changed
+		if !changed {
+			continue
+		}
+		if err := tb.file.DeleteDefinedName(&name); err != nil {
+			return fmt.Errorf("defined name %s: %w", name.Name, err)
+		}
+		if err := tb.file.SetDefinedName(&updated); err != nil {
+			return fmt.Errorf("defined name %s: %w", name.Name, err)
+		}
+	}
+	return nil
+}
+
+// processChartTitles substitutes the placeholders of chart and axis titles
+// and other rich text of charts
+func (tb *TemplateBuilder) processChartTitles(data map[string]interface{}) error {
+	return tb.editCharts(func(chart *xmlNode) bool {
+		changed := false
+		chart.walk(func(node *xmlNode) {
+			if localName(node.name) == "p" {
+				changed = tb.substituteParagraph(node, data) || changed
+			}
+		})
+		return changed
+	})
+}
+
+// substituteParagraph substitutes the placeholders of the text runs of a
+// rich text paragraph. Runs are substituted one by one when each holds
+// whole placeholders; a placeholder split across runs merges the text of
+// the paragraph into its first run, which keeps its formatting.
+func (tb *TemplateBuilder) substituteParagraph(paragraph *xmlNode, data map[string]interface{}) bool {
+	var runs, texts []*xmlNode
+	split := false
+	for _, run := range paragraph.elements("r") {
+		if text := run.element("t"); text != nil {
+			runs = append(runs, run)
+			texts = append(texts, text)
+			content := text.text()
+			split = split || strings.Count(content, "{{") != strings.Count(content, "}}")
+		}
+	}
+	if len(texts) == 0 {
+		return false
+	}
+
+	if !split {
+		changed := false
+		for _, text := range texts {
+			content := text.text()
+			if tb.substituteText(&content, data) {
+				text.setText(content)
+				changed = true
+			}
+		}
+		return changed
+	}
+
+	var joined strings.Builder
+	for _, text := range texts {
+		joined.WriteString(text.text())
+	}
+	content := joined.String()
+	if !tb.substituteText(&content, data) {
+		return false
+	}
+	texts[0].setText(content)
+	for _, run := range runs[1:] {
+		paragraph.remove(run)
+	}
+	return true
+}
+
+// editCharts parses the charts of the workbook, passes each to edit and
+// writes back the charts edit reports as changed
+func (tb *TemplateBuilder) editCharts(edit func(chart *xmlNode) bool) error {
+	paths, err := tb.chartParts()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		chart, err := loadXMLPart(tb.file, path)
+		if err != nil {
+			return err
+		}
+		if edit(chart) {
+			if err := storeXMLPart(tb.file, path, chart); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// chartParts returns the package paths of the charts of the workbook,
+// found through the drawings of its worksheets and chart sheets
+func (tb *TemplateBuilder) chartParts() ([]string, error) {
+	var paths []string
+	for _, sheetName := range tb.file.GetSheetList() {
+		sheetPath, _, err := sheetPart(tb.file, sheetName)
+		if err != nil {
+			return nil, err
+		}
+		drawings, err := relatedParts(tb.file, sheetPath, relTypeDrawing)
+		if err != nil {
+			return nil, err
+		}
+		for _, drawing := range drawings {
+			charts, err := relatedParts(tb.file, drawing, relTypeChart)
+			if err != nil {
+				return nil, err
+			}
+			for _, chart := range charts {
+				if indexOf(paths, chart) == -1 {
+					paths = append(paths, chart)
+				}
+			}
+		}
+	}
+	return paths, nil
+}
+
+// renameSheets substitutes the placeholders of sheet names. Names that are
+// not valid once substituted are reported and left unchanged.
+func (tb *TemplateBuilder) renameSheets(data map[string]interface{}) {
+	for _, sheetName := range tb.file.GetSheetList() {
+		name := sheetName
+		if !tb.substituteText(&name, data) {
+			continue
+		}
+		if err := validateSheetName(name); err != nil {
+			tb.excelBuilder.AddError(err)
+			continue
+		}
+		if err := tb.file.SetSheetName(sheetName, name); err != nil {
+			tb.excelBuilder.AddError(fmt.Errorf("failed to rename sheet %s: %w", sheetName, err))
+			continue
+		}
+		if err := tb.renameSheetReferences(sheetName, name); err != nil {
+			tb.excelBuilder.AddError(fmt.Errorf("failed to update references to sheet %s: %w", sheetName, err))
+		}
+		if tb.currentSheet == sheetName {
+			tb.currentSheet = name
+		}
+	}
+}
+
+// renameSheetReferences points the cell formulas and chart series referring
+// to a renamed sheet to its new name. excelize only updates defined names.
+func (tb *TemplateBuilder) renameSheetReferences(oldName, newName string) error {
+	rename := func(formula string) string {
+		return replaceSheetReference(formula, oldName, newName)
+	}
+
+	for _, sheetName := range tb.file.GetSheetList() {
+		lastCol, lastRow, err := tb.usedBounds(sheetName)
+		if err != nil {
+			return err
+		}
+		for row := 1; row <= lastRow; row++ {
+			for col := 1; col <= lastCol; col++ {
+				cellRef, _ := excelize.CoordinatesToCellName(col, row)
+				formula, err := tb.file.GetCellFormula(sheetName, cellRef)
+				if err != nil {
+					return err
+				}
+				if renamed := rename(formula); renamed != formula {
+					if err := tb.file.SetCellFormula(sheetName, cellRef, renamed); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return tb.editCharts(func(chart *xmlNode) bool {
+		changed := false
+		chart.walk(func(node *xmlNode) {
+			if localName(node.name) != "f" {
+				return
+			}
+			if formula := node.text(); rename(formula) != formula {
+				node.setText(rename(formula))
+				changed = true
+			}
+		})
+		return changed
+	})
+}
+
+// replaceSheetReference replaces the sheet prefix oldName! in a formula
+func replaceSheetReference(formula, oldName, newName string) string {
+	if !strings.Contains(formula, "!") {
+		return formula
+	}
+	target := quoteSheetName(newName) + "!"
+	formula = strings.ReplaceAll(formula, "'"+strings.ReplaceAll(oldName, "'", "''")+"'!", target)
+	if unquotedSheetName.MatchString(oldName) {
+		pattern := regexp.MustCompile(`(^|[^A-Za-z0-9_.'])` + regexp.QuoteMeta(oldName) + `!`)
+		formula = pattern.ReplaceAllString(formula, "${1}"+strings.ReplaceAll(target, "$", "$$"))
+	}
+	return formula
+}
+
+// quoteSheetName quotes a sheet name for use in a reference when needed
+func quoteSheetName(name string) string {
+	if unquotedSheetName.MatchString(name) {
+		return name
+	}
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
+// substituteText replaces the placeholders of text in place and reports
+// whether it changed
+func (tb *TemplateBuilder) substituteText(text *string, data map[string]interface{}) bool {
+	if !strings.Contains(*text, "{{") {
+		return false
+	}
+	replaced := tb.replacePlaceholders(*text, data)
+	if replaced == *text {
+		return false
+	}
+	*text = replaced
+	return true
+}
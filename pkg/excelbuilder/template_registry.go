@@ -0,0 +1,357 @@
+package excelbuilder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// metadataSuffix ends the base name of template metadata files that are
+// not named like their xlsx file
+const metadataSuffix = ".meta"
+
+// TemplateRegistry holds the xlsx templates of a file system, such as an
+// embed.FS, keyed by name and version
+type TemplateRegistry struct {
+	templates map[string][]*RegisteredTemplate // Versions in ascending order
+}
+
+// RegisteredTemplate is a template of a TemplateRegistry. Every call to
+// Open or Render works on a fresh copy of the template.
+type RegisteredTemplate struct {
+	Metadata   TemplateMetadata
+	content    []byte
+	referenced []string // Placeholder paths found in the template
+	required   []string // Referenced paths outside loops without a leading default filter
+}
+
+// NewTemplateRegistry loads the templates of fsys. A metadata file
+// describes the name, version, file and required placeholders of a
+// template: either <name>.meta.yaml (or .meta.yml, .meta.json) or a .yaml,
+// .yml or .json sidecar named like an xlsx file in the same directory.
+// Other YAML and JSON files are ignored. The name of a template defaults to
+// the metadata file name and its file to the xlsx file of the same name.
+// xlsx files without metadata are registered under their file name with an
+// empty version.
+//
+//	name: invoice
+//	version: "2"
+//	file: invoice-v2.xlsx
+//	required: [customer.name, total]
+func NewTemplateRegistry(fsys fs.FS) (*TemplateRegistry, error) {
+	var dataFiles, workbookFiles []string
+	err := fs.WalkDir(fsys, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		// Skip the lock files Excel leaves next to open workbooks
+		if strings.HasPrefix(entry.Name(), "~$") {
+			return nil
+		}
+		switch strings.ToLower(path.Ext(filePath)) {
+		case ".yaml", ".yml", ".json":
+			dataFiles = append(dataFiles, filePath)
+		case ".xlsx":
+			workbookFiles = append(workbookFiles, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	var metadataFiles []string
+	for _, dataFile := range dataFiles {
+		baseName := strings.TrimSuffix(dataFile, path.Ext(dataFile))
+		if strings.HasSuffix(strings.ToLower(baseName), metadataSuffix) || containsString(workbookFiles, baseName+".xlsx") {
+			metadataFiles = append(metadataFiles, dataFile)
+		}
+	}
+
+	registry := &TemplateRegistry{templates: make(map[string][]*RegisteredTemplate)}
+	described := make(map[string]bool)
+	for _, metadataFile := range metadataFiles {
+		content, err := fs.ReadFile(fsys, metadataFile)
+		if err != nil {
+			return nil, err
+		}
+		var metadata TemplateMetadata
+		if err := decodeSpecDocument(content, &metadata); err != nil {
+			return nil, fmt.Errorf("invalid template metadata %s: %w", metadataFile, err)
+		}
+
+		baseName := strings.TrimSuffix(path.Base(metadataFile), path.Ext(metadataFile))
+		if strings.HasSuffix(strings.ToLower(baseName), metadataSuffix) {
+			baseName = baseName[:len(baseName)-len(metadataSuffix)]
+		}
+		if metadata.Name == "" {
+			metadata.Name = baseName
+		}
+		if metadata.File == "" {
+			metadata.File = baseName + ".xlsx"
+		}
+		metadata.File = path.Join(path.Dir(metadataFile), metadata.File)
+		described[metadata.File] = true
+		if err := registry.register(fsys, metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, workbookFile := range workbookFiles {
+		if described[workbookFile] {
+			continue
+		}
+		metadata := TemplateMetadata{
+			Name: strings.TrimSuffix(path.Base(workbookFile), path.Ext(workbookFile)),
+			File: workbookFile,
+		}
+		if err := registry.register(fsys, metadata); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// register reads and scans the template file of metadata
+func (r *TemplateRegistry) register(fsys fs.FS, metadata TemplateMetadata) error {
+	for _, existing := range r.templates[metadata.Name] {
+		if existing.Metadata.Version == metadata.Version {
+			return fmt.Errorf("template %s version '%s' is defined by both %s and %s", metadata.Name, metadata.Version, existing.Metadata.File, metadata.File)
+		}
+	}
+
+	content, err := fs.ReadFile(fsys, metadata.File)
+	if err != nil {
+		return fmt.Errorf("template %s: %w", metadata.Name, err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("template %s: failed to open %s: %w", metadata.Name, metadata.File, err)
+	}
+	defer file.Close()
+
+	template := &RegisteredTemplate{Metadata: metadata, content: content}
+	if err := template.scanPlaceholders(file); err != nil {
+		return fmt.Errorf("template %s: %w", metadata.Name, err)
+	}
+
+	versions := append(r.templates[metadata.Name], template)
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareVersions(versions[i].Metadata.Version, versions[j].Metadata.Version) < 0
+	})
+	r.templates[metadata.Name] = versions
+	return nil
+}
+
+// Get returns the template with the given name and version. An empty
+// version selects the latest one.
+func (r *TemplateRegistry) Get(name, version string) (*RegisteredTemplate, error) {
+	versions := r.templates[name]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("template %s not found", name)
+	}
+	if version == "" {
+		return versions[len(versions)-1], nil
+	}
+	for _, template := range versions {
+		if template.Metadata.Version == version {
+			return template, nil
+		}
+	}
+	return nil, fmt.Errorf("template %s version '%s' not found", name, version)
+}
+
+// Names returns the names of the registered templates in sorted order
+func (r *TemplateRegistry) Names() []string {
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Versions returns the versions of a template from oldest to latest
+func (r *TemplateRegistry) Versions(name string) []string {
+	var versions []string
+	for _, template := range r.templates[name] {
+		versions = append(versions, template.Metadata.Version)
+	}
+	return versions
+}
+
+// Placeholders returns the placeholder paths found in the template,
+// including those inside loops
+func (t *RegisteredTemplate) Placeholders() []string {
+	return append([]string(nil), t.referenced...)
+}
+
+// CheckData compares data with the placeholders of the template. Missing
+// lists the required placeholders data does not supply: Metadata.Required,
+// or else the placeholders outside loops without a leading default filter.
+// Unused lists the top-level keys of data that no placeholder refers to.
+func (t *RegisteredTemplate) CheckData(data map[string]interface{}) TemplateDataReport {
+	report := TemplateDataReport{}
+
+	required := t.Metadata.Required
+	if len(required) == 0 {
+		required = t.required
+	}
+	for _, placeholder := range required {
+		if _, found := resolvePath(data, placeholder); !found {
+			report.Missing = append(report.Missing, placeholder)
+		}
+	}
+
+	referenced := append(append([]string(nil), t.referenced...), t.Metadata.Required...)
+	for key := range data {
+		used := false
+		for _, placeholder := range referenced {
+			if placeholder == key || strings.HasPrefix(placeholder, key+".") || strings.HasPrefix(placeholder, key+"[") {
+				used = true
+				break
+			}
+		}
+		if !used {
+			report.Unused = append(report.Unused, key)
+		}
+	}
+	sort.Strings(report.Unused)
+	return report
+}
+
+// Open returns a TemplateBuilder on a fresh copy of the template
+func (t *RegisteredTemplate) Open() (*TemplateBuilder, error) {
+	return LoadTemplate(bytes.NewReader(t.content))
+}
+
+// Render checks that data supplies every required placeholder and returns
+// a fresh copy of the template processed with data. Errors met while
+// processing, such as an unclosed loop or an unknown filter, are returned
+// joined together.
+func (t *RegisteredTemplate) Render(data map[string]interface{}) (*TemplateBuilder, error) {
+	if report := t.CheckData(data); len(report.Missing) > 0 {
+		return nil, fmt.Errorf("template %s: missing data for %s", t.Metadata.Name, strings.Join(report.Missing, ", "))
+	}
+	tb, err := t.Open()
+	if err != nil {
+		return nil, err
+	}
+	tb.ProcessTemplate(data)
+	if errs := tb.Errors(); len(errs) > 0 {
+		tb.file.Close()
+		return nil, fmt.Errorf("template %s: %w", t.Metadata.Name, errors.Join(errs...))
+	}
+	return tb, nil
+}
+
+// scanPlaceholders collects the placeholder paths of the cells, sheet names
+// and headers and footers of the template. Placeholders between
+// {{#each key}} and {{/each}} resolve against the loop items, so they are
+// never required; only the loop key is.
+func (t *RegisteredTemplate) scanPlaceholders(file *excelize.File) error {
+	seen := make(map[string]bool)
+	record := func(text string, inLoop bool) {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			placeholder, optional := placeholderPath(match[1])
+			if placeholder == "" {
+				continue
+			}
+			if !seen[placeholder] {
+				seen[placeholder] = true
+				t.referenced = append(t.referenced, placeholder)
+			}
+			if !inLoop && !optional && !containsString(t.required, placeholder) {
+				t.required = append(t.required, placeholder)
+			}
+		}
+	}
+
+	for _, sheetName := range file.GetSheetList() {
+		record(sheetName, false)
+
+		opts, err := file.GetHeaderFooter(sheetName)
+		if err != nil {
+			return err
+		}
+		if opts != nil {
+			for _, text := range []string{opts.OddHeader, opts.OddFooter, opts.EvenHeader, opts.EvenFooter, opts.FirstHeader, opts.FirstFooter} {
+				record(text, false)
+			}
+		}
+
+		rows, err := file.GetRows(sheetName)
+		if err != nil {
+			return err
+		}
+		depth := 0
+		for _, row := range rows {
+			for _, cellValue := range row {
+				for _, match := range eachStartPattern.FindAllStringSubmatch(cellValue, -1) {
+					record("{{"+match[1]+"}}", depth > 0)
+					depth++
+				}
+			}
+			for _, cellValue := range row {
+				cellValue = eachStartPattern.ReplaceAllString(cellValue, "")
+				record(eachEndPattern.ReplaceAllString(cellValue, ""), depth > 0)
+			}
+			for _, cellValue := range row {
+				depth -= len(eachEndPattern.FindAllString(cellValue, -1))
+			}
+		}
+	}
+
+	sort.Strings(t.referenced)
+	sort.Strings(t.required)
+	return nil
+}
+
+// placeholderPath returns the data path of a placeholder expression and
+// whether a leading default filter makes it optional. Loop variables have no path.
+func placeholderPath(expression string) (string, bool) {
+	expression = strings.TrimSpace(expression)
+	if match := imagePlaceholderPattern.FindStringSubmatch("{{" + expression + "}}"); match != nil {
+		return strings.TrimSpace(match[1]), false
+	}
+
+	parts, err := splitPipes(expression)
+	if err != nil {
+		return "", false
+	}
+	placeholder := strings.TrimSpace(parts[0])
+	switch placeholder {
+	case "this", "@index", "@row":
+		return "", false
+	}
+
+	return placeholder, len(parts) > 1 && isDefaultFilter(parts[1])
+}
+
+// compareVersions orders versions such as "1.2" and "1.10" by their
+// numeric components, falling back to text comparison
+func compareVersions(a, b string) int {
+	partsA := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	partsB := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numberA, errA := strconv.Atoi(partsA[i])
+		numberB, errB := strconv.Atoi(partsB[i])
+		switch {
+		case errA == nil && errB == nil && numberA != numberB:
+			if numberA < numberB {
+				return -1
+			}
+			return 1
+		case (errA != nil || errB != nil) && partsA[i] != partsB[i]:
+			return strings.Compare(partsA[i], partsB[i])
+		}
+	}
+	return len(partsA) - len(partsB)
+}
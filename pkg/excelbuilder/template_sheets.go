@@ -0,0 +1,159 @@
+package excelbuilder
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// RepeatSheet clones templateSheet once per element of items, names each
+// copy with nameFunc and fills it with the element's data. Placeholders the
+// element does not define are kept, so call RepeatSheet before
+// ProcessTemplate to fill them with shared data. The copies keep the styles,
+// column widths, merges and print settings of the template and take its
+// place in the workbook; the template sheet itself is removed. Invalid or
+// duplicate names and failed copies leave the workbook unchanged and are
+// reported through Errors.
+func (tb *TemplateBuilder) RepeatSheet(templateSheet string, items interface{}, nameFunc func(index int, item interface{}) string) *TemplateBuilder {
+	if err := tb.repeatSheet(templateSheet, items, nameFunc); err != nil {
+		tb.excelBuilder.AddError(fmt.Errorf("failed to repeat sheet %s: %w", templateSheet, err))
+	}
+	return tb
+}
+
+// repeatSheet creates and fills the copies of a template sheet
+func (tb *TemplateBuilder) repeatSheet(templateSheet string, items interface{}, nameFunc func(index int, item interface{}) string) error {
+	list, ok := sliceItems(items)
+	if !ok {
+		return fmt.Errorf("items must be a slice, got %T", items)
+	}
+	templateIndex, err := tb.file.GetSheetIndex(templateSheet)
+	if err != nil {
+		return err
+	}
+	if templateIndex == -1 {
+		return fmt.Errorf("sheet %s does not exist", templateSheet)
+	}
+	wasActive := tb.file.GetActiveSheetIndex() == templateIndex
+
+	// Validate every name before touching the workbook
+	names := make([]string, len(list))
+	seen := map[string]bool{templateSheet: true}
+	for i, item := range list {
+		names[i] = nameFunc(i, item)
+		if err := validateSheetName(names[i]); err != nil {
+			return err
+		}
+		if index, _ := tb.file.GetSheetIndex(names[i]); seen[names[i]] || index != -1 {
+			return fmt.Errorf("sheet name '%s' is already used", names[i])
+		}
+		seen[names[i]] = true
+	}
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	// excelize identifies the scope of a defined name by sheet position and
+	// does not update it when sheets move, so scoped names such as print
+	// areas are set aside while the copies are created
+	scopedNames, err := tb.removeScopedNames()
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		if err := tb.copyTemplateSheet(templateSheet, name); err != nil {
+			// Drop the copies made so far and put the scoped names back
+			for _, created := range names[:i+1] {
+				if index, _ := tb.file.GetSheetIndex(created); index != -1 {
+					_ = tb.file.DeleteSheet(created)
+				}
+			}
+			for _, definedName := range scopedNames {
+				_ = tb.file.SetDefinedName(&definedName)
+			}
+			return err
+		}
+	}
+	if err := tb.file.DeleteSheet(templateSheet); err != nil {
+		return err
+	}
+	for _, definedName := range scopedNames {
+		targets := []string{definedName.Scope}
+		if definedName.Scope == templateSheet {
+			targets = names
+		}
+		for _, target := range targets {
+			restored := definedName
+			restored.Scope = target
+			restored.RefersTo = replaceSheetReference(definedName.RefersTo, definedName.Scope, target)
+			if err := tb.file.SetDefinedName(&restored); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, item := range list {
+		scope := loopScope(tb.templateData, item, i)
+		if err := tb.expandLoops(names[i], scope); err != nil {
+			return err
+		}
+		tb.processSheet(names[i], scope)
+		if err := tb.processSheetParts(names[i], scope); err != nil {
+			return err
+		}
+	}
+
+	if wasActive {
+		index, _ := tb.file.GetSheetIndex(names[0])
+		tb.file.SetActiveSheet(index)
+	}
+	if tb.currentSheet == templateSheet {
+		tb.currentSheet = names[0]
+	}
+	return nil
+}
+
+// removeScopedNames deletes the defined names scoped to a sheet and returns them
+func (tb *TemplateBuilder) removeScopedNames() ([]excelize.DefinedName, error) {
+	var scoped []excelize.DefinedName
+	for _, definedName := range tb.file.GetDefinedName() {
+		if definedName.Scope == "" || definedName.Scope == "Workbook" {
+			continue
+		}
+		if err := tb.file.DeleteDefinedName(&definedName); err != nil {
+			return nil, err
+		}
+		scoped = append(scoped, definedName)
+	}
+	return scoped, nil
+}
+
+// copyTemplateSheet copies a sheet to a new sheet placed before it
+func (tb *TemplateBuilder) copyTemplateSheet(templateSheet, name string) error {
+	index, err := tb.file.NewSheet(name)
+	if err != nil {
+		return err
+	}
+	// The template index changes as copies are moved before it
+	templateIndex, err := tb.file.GetSheetIndex(templateSheet)
+	if err != nil {
+		return err
+	}
+	if err := tb.file.CopySheet(templateIndex, index); err != nil {
+		return err
+	}
+	if err := tb.file.MoveSheet(name, templateSheet); err != nil {
+		return err
+	}
+
+	// excelize drops the page setup when copying a sheet
+	layout, err := tb.file.GetPageLayout(templateSheet)
+	if err != nil {
+		return err
+	}
+	if err := tb.file.SetPageLayout(name, &layout); err != nil {
+		return err
+	}
+	return nil
+}
@@ -110,9 +110,59 @@ type ChartFormat struct {
 	YScale    float64
 	XOffset   float64
 	YOffset   float64
-	PrintObj  bool
+	PrintObj  *bool // Whether the chart is printed; defaults to true
 	LockRatio bool
-	Locked    bool
+	Locked    *bool // Whether the chart is locked with the sheet; defaults to false
+}
+
+// SlicerOptions defines the appearance of a slicer
+type SlicerOptions struct {
+	Caption    string // Defaults to the field name
+	Sheet      string // Sheet receiving the slicer; defaults to the sheet of the table
+	Width      uint   // Pixels
+	Height     uint   // Pixels
+	Descending bool
+	HideHeader bool
+}
+
+// SlicerConfig defines a slicer filtering a table or pivot table field
+type SlicerConfig struct {
+	Field   string
+	Cell    string
+	Options SlicerOptions
+}
+
+// TableConfig defines an Excel table
+type TableConfig struct {
+	Name              string
+	Range             string
+	Style             string
+	ShowFirstColumn   bool
+	ShowLastColumn    bool
+	ShowRowStripes    bool
+	ShowColumnStripes bool
+	Slicers           []SlicerConfig
+}
+
+// SparklineOptions defines the appearance of sparklines
+type SparklineOptions struct {
+	Type          string // "line", "column", "win_loss"
+	Style         int    // Predefined style, 0 - 35
+	Markers       bool
+	High          bool
+	Low           bool
+	First         bool
+	Last          bool
+	Negative      bool
+	Axis          bool
+	Reverse       bool
+	SeriesColor   string
+	NegativeColor string
+	MarkersColor  string
+	FirstColor    string
+	LastColor     string
+	HighColor     string
+	LowColor      string
 }
 
 // ColorScale defines color scale configuration for conditional formatting
@@ -184,10 +234,41 @@ type DataValidationConfig struct {
 
 // SheetTemplate defines a sheet template configuration
 type SheetTemplate struct {
-	Name      string
-	Headers   []string
-	Styles    map[string]StyleConfig
-	Variables map[string]interface{}
+	Name        string
+	Headers     []string // Column headers used when Columns is empty
+	Styles      map[string]StyleConfig
+	Variables   map[string]interface{}
+	Data        string // Key of the data list written one record per row
+	Columns     []ColumnTemplate
+	HeaderStyle string // Name of the style applied to the header row
+	Charts      []ChartTemplate
+	Validations []ValidationTemplate
+}
+
+// ColumnTemplate defines a column of a sheet template
+type ColumnTemplate struct {
+	Header       string
+	Value        string // Placeholder expression evaluated per record, e.g. "customer.name | upper"; defaults to Header
+	Formula      string // Formula written instead of a value; {{@row}} is the row number
+	Width        float64
+	Style        string // Name of the style applied to the data cells
+	NumberFormat string
+}
+
+// ChartTemplate defines a chart of a sheet template. Series categories,
+// values and names may name a column header instead of a cell range.
+type ChartTemplate struct {
+	ChartConfig
+	Position string // Top-left cell of the chart
+	Anchor   string // Cell range the chart fills, overriding Position and size
+}
+
+// ValidationTemplate defines a data validation of a sheet template, applied
+// to the data cells of Column or to Range
+type ValidationTemplate struct {
+	DataValidationConfig
+	Column string
+	Range  string
 }
 
 // TemplateConfig defines template configuration
@@ -197,6 +278,23 @@ type TemplateConfig struct {
 	Path        string
 	Sheets      []SheetTemplate
 	Variables   map[string]interface{}
+	Styles      map[string]StyleConfig // Named styles shared by all sheets
+}
+
+// TemplateMetadata describes a template file of a TemplateRegistry
+type TemplateMetadata struct {
+	Name        string
+	Version     string
+	File        string // Path of the xlsx file, relative to the metadata file
+	Description string
+	Required    []string // Placeholder paths the data must supply; defaults to the placeholders found in the template
+}
+
+// TemplateDataReport lists the differences between the placeholders of a
+// template and the data supplied to it
+type TemplateDataReport struct {
+	Missing []string // Required placeholders the data does not supply
+	Unused  []string // Top-level data keys no placeholder refers to
 }
 
 // FormulaConfig defines advanced formula configuration
@@ -305,10 +403,20 @@ const (
 
 // CSVOptions defines options for CSV import/export
 type CSVOptions struct {
-	Delimiter string
+	Delimiter string // Sniffed from the data on import when empty; comma on export
 	Quote     rune
 	Comment   rune
 	SkipRows  int
+	Encoding  string // Import encoding such as "windows-1252", "utf-16" or "shift_jis"; detected when empty or "auto"
+	Locale    string // Import number format locale such as "de-DE" for 1.234,56; 1,234.56 when empty
+	// Streaming import options
+	HasHeader  bool // Write the first row after SkipRows as a header, excluded from type inference
+	SampleRows int  // Rows sampled to infer column types; defaults to 100
+	// Export options
+	RawValues bool // Write unformatted cell values instead of displayed values
+	Formulas  bool // Write the formula of formula cells, e.g. "=SUM(A1:A3)", instead of their value
+	BOM       bool // Start the output with a UTF-8 byte order mark
+	UseCRLF   bool // End lines with \r\n instead of \n
 }
 
 // FlattenOptions defines options for flattening nested JSON structures
@@ -317,6 +425,17 @@ type FlattenOptions struct {
 	MaxDepth  int
 }
 
+// SQLImportOptions defines options for SheetBuilder.AddRowsFromSQL
+type SQLImportOptions struct {
+	SkipHeader     bool              // Leave out the header row of column names
+	HeaderStyle    *StyleConfig      // Style of the header row; bold when nil
+	ColumnHeaders  map[string]string // Header titles by column name; the column name when missing
+	DecimalFormat  string            // Number format of DECIMAL/NUMERIC columns; from the column scale when empty
+	DateFormat     string            // Number format of DATE columns; "yyyy-mm-dd" when empty
+	DateTimeFormat string            // Number format of TIMESTAMP/DATETIME columns; "yyyy-mm-dd hh:mm:ss" when empty
+	NullValue      string            // Text written for NULL values; empty cells when empty
+}
+
 // ImportHelper provides functionality to import data from various formats
 type ImportHelper struct {
 	data      interface{}
@@ -332,8 +451,24 @@ type ExportHelper struct {
 
 // PivotField defines a field in a pivot table
 type PivotField struct {
-	Name     string
-	Function string // For value fields: "sum", "count", "average", "max", "min", "product", "countNums", "stdDev", "stdDevp", "var", "varp"
+	Name         string
+	Function     string // For value fields: "sum", "count", "average", "max", "min", "product", "countNums", "stdDev", "stdDevp", "var", "varp"
+	Options      PivotFieldOptions
+	ValueOptions PivotValueFieldOptions
+}
+
+// PivotFieldOptions defines sorting and item filtering for row and column fields
+type PivotFieldOptions struct {
+	Sort        string   // "ascending" or "descending"
+	HiddenItems []string // Items excluded from the pivot table
+}
+
+// PivotValueFieldOptions defines how a value field is named and displayed
+type PivotValueFieldOptions struct {
+	DisplayName  string // Defaults to "<function> of <field>"
+	NumberFormat string // Custom number format, e.g. "#,##0.00" or "0.0%"
+	ShowValuesAs string // "normal", "percentOfTotal", "percentOfRow", "percentOfColumn", "percentOf", "difference", "percentDifference", "runningTotal", "index"
+	BaseField    string // Base field for "runningTotal", "difference", "percentOf" and "percentDifference"
 }
 
 // PivotTableConfig defines the configuration for a pivot table
@@ -353,6 +488,22 @@ type PivotTableConfig struct {
 	Compact               bool
 	Outline               bool
 	Subtotals             bool
+	DerivedFields         []PivotDerivedField
+	Slicers               []SlicerConfig
+}
+
+// PivotSpec defines a pivot table built directly from Go records
+type PivotSpec struct {
+	Name         string
+	SourceSheet  string // Hidden sheet receiving the records; defaults to "<TargetSheet>_Data"
+	TargetSheet  string
+	TargetCell   string   // Defaults to "A1"
+	Columns      []string // Keys or struct fields written, in order; defaults to all of them
+	RowFields    []PivotField
+	ColumnFields []PivotField
+	ValueFields  []PivotField
+	FilterFields []PivotField
+	Style        string
 }
 
 // Advanced Layout Management types
@@ -389,10 +540,14 @@ type AutoFitConfig struct {
 	IgnoreEmpty bool
 }
 
+// AggregationFunc aggregates the non-nil values of a pivot cell
+type AggregationFunc func(values []interface{}) interface{}
+
 // PivotConfig defines configuration for data pivoting
 type PivotConfig struct {
-	RowFields    []string
-	ColumnFields []string
-	ValueFields  []string
-	Aggregation  string
+	RowFields         []string
+	ColumnFields      []string
+	ValueFields       []string
+	Aggregation       string // "sum" (default), "count", "avg", "min", "max", "distinct", "custom"
+	CustomAggregation AggregationFunc
 }
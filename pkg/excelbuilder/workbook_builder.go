@@ -1,6 +1,7 @@
 package excelbuilder
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -29,7 +30,9 @@ func (wb *WorkbookBuilder) SetProperties(props WorkbookProperties) *WorkbookBuil
 	return wb
 }
 
-// AddSheet creates a new sheet and returns a SheetBuilder
+// AddSheet creates a new sheet and returns a SheetBuilder. When the sheet
+// already exists, as in an opened workbook, rows are added after its last
+// used row like GetSheet does instead of overwriting it.
 func (wb *WorkbookBuilder) AddSheet(name string) *SheetBuilder {
 	if name == "" {
 		wb.excelBuilder.AddError(fmt.Errorf("sheet name cannot be empty"))
@@ -42,23 +45,8 @@ func (wb *WorkbookBuilder) AddSheet(name string) *SheetBuilder {
 		}
 	}
 
-	// Check for invalid characters
-	invalidChars := []string{"[", "]", "*", "?", "/", "\\"}
-	for _, char := range invalidChars {
-		if strings.Contains(name, char) {
-			wb.excelBuilder.AddError(fmt.Errorf("sheet name '%s' contains invalid character '%s'", name, char))
-			return &SheetBuilder{
-				workbookBuilder: wb,
-				sheetName:       "Sheet1", // fallback name
-				currentRow:      0,
-				hasError:        true,
-			}
-		}
-	}
-
-	// Check length (Excel limit is 31 characters)
-	if len(name) > 31 {
-		wb.excelBuilder.AddError(fmt.Errorf("sheet name '%s' exceeds 31 character limit", name))
+	if err := validateSheetName(name); err != nil {
+		wb.excelBuilder.AddError(err)
 		return &SheetBuilder{
 			workbookBuilder: wb,
 			sheetName:       "Sheet1", // fallback name
@@ -67,18 +55,10 @@ func (wb *WorkbookBuilder) AddSheet(name string) *SheetBuilder {
 		}
 	}
 
-	// Check for reserved names
-	reservedNames := []string{"History", "Print_Area", "Print_Titles"}
-	for _, reserved := range reservedNames {
-		if name == reserved {
-			wb.excelBuilder.AddError(fmt.Errorf("sheet name '%s' is reserved", name))
-			return &SheetBuilder{
-				workbookBuilder: wb,
-				sheetName:       "Sheet1", // fallback name
-				currentRow:      0,
-				hasError:        true,
-			}
-		}
+	existing, err := wb.file.GetSheetIndex(name)
+	if err == nil && existing != -1 {
+		wb.file.SetActiveSheet(existing)
+		return wb.GetSheet(name)
 	}
 
 	// Create the sheet
@@ -104,6 +84,154 @@ func (wb *WorkbookBuilder) AddSheet(name string) *SheetBuilder {
 	}
 }
 
+// GetSheet returns a SheetBuilder for an existing sheet, positioned after
+// its last used row so AddRow appends to the sheet
+func (wb *WorkbookBuilder) GetSheet(name string) *SheetBuilder {
+	index, err := wb.file.GetSheetIndex(name)
+	if err == nil && index == -1 {
+		err = fmt.Errorf("sheet '%s' does not exist", name)
+	}
+	if err != nil {
+		wb.excelBuilder.AddError(err)
+		// Keep the requested name so later writes fail instead of
+		// landing on another sheet
+		return &SheetBuilder{
+			workbookBuilder: wb,
+			sheetName:       name,
+			currentRow:      0,
+			hasError:        true,
+		}
+	}
+
+	lastRow, err := lastUsedRow(wb.file, name)
+	if err != nil {
+		wb.excelBuilder.AddError(fmt.Errorf("failed to read sheet '%s': %w", name, err))
+	}
+	return &SheetBuilder{
+		workbookBuilder: wb,
+		sheetName:       name,
+		currentRow:      lastRow,
+		hasError:        err != nil,
+	}
+}
+
+// lastUsedRow returns the number of the last row of a sheet holding a
+// value or formula, or 0 for an empty sheet. Rows holding only formatting
+// do not count.
+func lastUsedRow(file *excelize.File, sheetName string) (int, error) {
+	rows, err := file.Rows(sheetName)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	lastRow, rowNumber := 0, 0
+	for rows.Next() {
+		rowNumber++
+		columns, err := rows.Columns()
+		if err != nil {
+			return 0, err
+		}
+		if len(columns) > 0 {
+			lastRow = rowNumber
+		}
+	}
+	return lastRow, rows.Error()
+}
+
+// AddChartSheet creates a chart sheet, a sheet that contains only a single
+// full-page chart, and returns a ChartBuilder to configure it.
+// The chart sheet is created when the ChartBuilder is built.
+func (wb *WorkbookBuilder) AddChartSheet(name string) *ChartBuilder {
+	cb := NewChartBuilder(wb.file, name)
+	cb.chartSheet = true
+
+	if name == "" {
+		cb.err = fmt.Errorf("sheet name cannot be empty")
+	} else if err := validateSheetName(name); err != nil {
+		cb.err = err
+	}
+	if cb.err != nil {
+		wb.excelBuilder.AddError(cb.err)
+	}
+
+	return cb
+}
+
+// AddPivotFromRecords writes records (a slice of maps or structs) to a hidden
+// source sheet and builds a pivot table over exactly the written range.
+// The source sheet is removed again when the pivot table cannot be built.
+func (wb *WorkbookBuilder) AddPivotFromRecords(records interface{}, spec PivotSpec) (err error) {
+	if spec.TargetSheet == "" {
+		return fmt.Errorf("pivot target sheet cannot be empty")
+	}
+	header, rows, err := NewDataTransformer().RecordsToRows(records, spec.Columns)
+	if err != nil {
+		return fmt.Errorf("failed to convert pivot records: %w", err)
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("no records to pivot")
+	}
+
+	sourceSheet := spec.SourceSheet
+	if sourceSheet == "" {
+		sourceSheet = spec.TargetSheet + "_Data"
+	}
+	if err := validateSheetName(sourceSheet); err != nil {
+		return err
+	}
+	if index, _ := wb.file.GetSheetIndex(sourceSheet); index != -1 {
+		return fmt.Errorf("pivot source sheet '%s' already exists", sourceSheet)
+	}
+
+	// Keep the currently active sheet active, the source sheet is hidden
+	activeSheet := wb.file.GetActiveSheetIndex()
+	sheet := wb.AddSheet(sourceSheet)
+	if sheet.hasError {
+		return fmt.Errorf("failed to create pivot source sheet '%s'", sourceSheet)
+	}
+	// Leave no source sheet behind so that a failed call can be retried
+	defer func() {
+		if err == nil {
+			return
+		}
+		if deleteErr := wb.file.DeleteSheet(sourceSheet); deleteErr != nil {
+			err = errors.Join(err, deleteErr)
+		}
+		wb.file.SetActiveSheet(activeSheet)
+	}()
+	headerRow := sheet.AddRow()
+	for _, column := range header {
+		headerRow.AddCell(column)
+	}
+	sheet.AddRows(rows)
+	wb.file.SetActiveSheet(activeSheet)
+	if err := wb.file.SetSheetVisible(sourceSheet, false); err != nil {
+		return fmt.Errorf("failed to hide pivot source sheet '%s': %w", sourceSheet, err)
+	}
+
+	lastCell, err := excelize.CoordinatesToCellName(len(header), len(rows)+1)
+	if err != nil {
+		return fmt.Errorf("failed to compute pivot source range: %w", err)
+	}
+	pivot := sheet.NewPivotTable(spec.TargetSheet, fmt.Sprintf("%s!A1:%s", sourceSheet, lastCell))
+	pivot.config.RowFields = append(pivot.config.RowFields, spec.RowFields...)
+	pivot.config.ColumnFields = append(pivot.config.ColumnFields, spec.ColumnFields...)
+	pivot.config.ValueFields = append(pivot.config.ValueFields, spec.ValueFields...)
+	pivot.config.FilterFields = append(pivot.config.FilterFields, spec.FilterFields...)
+	if spec.Name != "" {
+		pivot.SetName(spec.Name)
+	}
+	if spec.TargetCell != "" {
+		pivot.SetTargetCell(spec.TargetCell)
+	}
+	if spec.Style != "" {
+		pivot.WithStyle(spec.Style)
+	}
+
+	return pivot.Build()
+}
+
 // AddSheetsBatch creates multiple sheets with data in a single call.
 func (wb *WorkbookBuilder) AddSheetsBatch(sheets []SheetConfig) *WorkbookBuilder {
 	for _, sheetConfig := range sheets {
@@ -130,3 +258,29 @@ func (wb *WorkbookBuilder) SetActiveSheet(name string) *WorkbookBuilder {
 func (wb *WorkbookBuilder) Build() *excelize.File {
 	return wb.file
 }
+
+// validateSheetName checks a sheet name against Excel's naming rules
+func validateSheetName(name string) error {
+	// Check for invalid characters
+	invalidChars := []string{"[", "]", "*", "?", "/", "\\"}
+	for _, char := range invalidChars {
+		if strings.Contains(name, char) {
+			return fmt.Errorf("sheet name '%s' contains invalid character '%s'", name, char)
+		}
+	}
+
+	// Check length (Excel limit is 31 characters)
+	if len(name) > 31 {
+		return fmt.Errorf("sheet name '%s' exceeds 31 character limit", name)
+	}
+
+	// Check for reserved names
+	reservedNames := []string{"History", "Print_Area", "Print_Titles"}
+	for _, reserved := range reservedNames {
+		if name == reserved {
+			return fmt.Errorf("sheet name '%s' is reserved", name)
+		}
+	}
+
+	return nil
+}
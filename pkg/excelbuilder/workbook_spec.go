@@ -0,0 +1,367 @@
+package excelbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadWorkbookSpec reads a workbook specification written in YAML or JSON.
+// Keys match the field names of TemplateConfig and SheetTemplate
+// case-insensitively, so both "numberFormat" and "NumberFormat" work.
+// Unknown keys are reported as errors to catch typos early.
+//
+//	name: Sales report
+//	styles:
+//	  header: {font: {bold: true}, fill: {type: pattern, color: "#DDEBF7"}}
+//	sheets:
+//	  - name: "Sales {{year}}"
+//	    data: orders
+//	    headerStyle: header
+//	    columns:
+//	      - {header: Customer, value: customer.name, width: 24}
+//	      - {header: Amount, value: amount, numberFormat: "#,##0.00"}
+//	    charts:
+//	      - type: col
+//	        title: Sales
+//	        position: E2
+//	        dataSeries: [{name: Amount, categories: Customer, values: Amount}]
+func LoadWorkbookSpec(r io.Reader) (*TemplateConfig, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workbook spec: %w", err)
+	}
+
+	spec := &TemplateConfig{}
+	if err := decodeSpecDocument(content, spec); err != nil {
+		return nil, fmt.Errorf("invalid workbook spec: %w", err)
+	}
+	if err := validateWorkbookSpec(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Render builds the workbook described by spec. Placeholders in sheet
+// names, headers, chart titles and formulas are substituted with data,
+// which takes precedence over the spec and sheet variables. Sheets with a
+// data binding get one row per element of the bound list.
+func Render(spec *TemplateConfig, data map[string]interface{}) (*excelize.File, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("workbook spec cannot be nil")
+	}
+	if err := validateWorkbookSpec(spec); err != nil {
+		return nil, err
+	}
+
+	eb := New().WithErrorCollection(true)
+	workbook := eb.NewWorkbook()
+
+	if spec.Name != "" {
+		workbook.SetProperties(WorkbookProperties{
+			Title:       spec.Name,
+			Description: spec.Description,
+		})
+	}
+
+	var sheetNames []string
+	for i, sheet := range spec.Sheets {
+		scope := mergeVariables(spec.Variables, sheet.Variables, data)
+		name, err := renderSheet(workbook, spec, sheet, scope)
+		if err != nil {
+			return nil, fmt.Errorf("sheet %d (%s): %w", i+1, sheet.Name, err)
+		}
+		sheetNames = append(sheetNames, name)
+	}
+
+	file := workbook.Build()
+	if !containsString(sheetNames, "Sheet1") {
+		if err := file.DeleteSheet("Sheet1"); err != nil {
+			return nil, err
+		}
+	}
+	if index, err := file.GetSheetIndex(sheetNames[0]); err == nil {
+		file.SetActiveSheet(index)
+	}
+
+	if errs := eb.GetCollectedErrors(); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return file, nil
+}
+
+// renderSheet writes one sheet of the spec and returns its name.
+// Placeholders are evaluated with the built-in template filters.
+func renderSheet(workbook *WorkbookBuilder, spec *TemplateConfig, sheet SheetTemplate, scope map[string]interface{}) (string, error) {
+	addError := workbook.excelBuilder.AddError
+	name := substitutePlaceholders(sheet.Name, scope, nil, addError)
+	if err := validateSheetName(name); err != nil {
+		return "", err
+	}
+	sb := workbook.AddSheet(name)
+	if sb.hasError {
+		return "", fmt.Errorf("failed to create sheet %s", name)
+	}
+
+	columns := sheetColumns(sheet)
+	styleOf := func(styleName string) StyleConfig {
+		if style, ok := sheet.Styles[styleName]; ok {
+			return style
+		}
+		return spec.Styles[styleName]
+	}
+
+	if len(columns) > 0 {
+		row := sb.AddRow()
+		for _, column := range columns {
+			cell := row.AddCell(substitutePlaceholders(column.Header, scope, nil, addError))
+			if sheet.HeaderStyle != "" {
+				cell.WithStyle(styleOf(sheet.HeaderStyle))
+			}
+		}
+	}
+	firstDataRow := sb.currentRow + 1
+
+	if sheet.Data != "" {
+		items, exists, err := loopItems(scope, sheet.Data)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", fmt.Errorf("data '%s' not found", sheet.Data)
+		}
+
+		columnStyles := make([]StyleConfig, len(columns))
+		for i, column := range columns {
+			columnStyles[i] = styleOf(column.Style)
+			if column.NumberFormat != "" {
+				columnStyles[i].NumberFormat = column.NumberFormat
+			}
+		}
+
+		for i, item := range items {
+			row := sb.AddRow()
+			rowScope := loopScope(scope, item, i)
+			rowScope["@row"] = row.rowIndex
+			for j, column := range columns {
+				var cell *CellBuilder
+				if column.Formula != "" {
+					cell = row.AddCell(nil).WithFormula(substitutePlaceholders(column.Formula, rowScope, nil, addError))
+				} else {
+					expression := column.Value
+					if expression == "" {
+						expression = column.Header
+					}
+					value, _, err := evaluateExpression(expression, rowScope, nil)
+					if err != nil {
+						return "", fmt.Errorf("column %s: %w", column.Header, err)
+					}
+					cell = row.AddCell(value)
+				}
+				if columnStyles[j] != (StyleConfig{}) {
+					cell.WithStyle(columnStyles[j])
+				}
+			}
+		}
+	}
+	lastDataRow := sb.currentRow
+	if lastDataRow < firstDataRow {
+		lastDataRow = firstDataRow
+	}
+
+	for i, column := range columns {
+		if column.Width > 0 {
+			colName, _ := excelize.ColumnNumberToName(i + 1)
+			sb.SetColumnWidth(colName, column.Width)
+		}
+	}
+
+	file := workbook.file
+	for _, validation := range sheet.Validations {
+		sqref := validation.Range
+		if validation.Column != "" {
+			index := columnIndex(columns, validation.Column)
+			start, _ := excelize.CoordinatesToCellName(index, firstDataRow)
+			end, _ := excelize.CoordinatesToCellName(index, lastDataRow)
+			sqref = start + ":" + end
+		}
+		dv := newDataValidation(&validation.DataValidationConfig, sqref)
+		if err := file.AddDataValidation(name, dv); err != nil {
+			return "", fmt.Errorf("failed to add data validation to %s: %w", sqref, err)
+		}
+	}
+
+	for i, chart := range sheet.Charts {
+		if err := renderChart(sb, chart, columns, firstDataRow, lastDataRow, scope); err != nil {
+			return "", fmt.Errorf("chart %d: %w", i+1, err)
+		}
+	}
+	return name, nil
+}
+
+// renderChart adds a chart of the spec to the sheet, resolving series that
+// name a column header to the data range of that column
+func renderChart(sb *SheetBuilder, chart ChartTemplate, columns []ColumnTemplate, firstRow, lastRow int, scope map[string]interface{}) error {
+	columnRange := func(ref string, fromRow, toRow int) string {
+		index := columnIndex(columns, ref)
+		if index == 0 {
+			return ref
+		}
+		colName, _ := excelize.ColumnNumberToName(index)
+		return fmt.Sprintf("%s!$%s$%d:$%s$%d", quoteSheetName(sb.sheetName), colName, fromRow, colName, toRow)
+	}
+
+	cb := sb.AddChart().
+		SetType(chart.Type).
+		SetTitle(substitutePlaceholders(chart.Title, scope, nil, sb.workbookBuilder.excelBuilder.AddError)).
+		SetXAxis(chart.XAxis).
+		SetYAxis(chart.YAxis)
+	if chart.Width > 0 && chart.Height > 0 {
+		cb.SetDimensions(chart.Width, chart.Height)
+	}
+	if chart.Legend != (LegendConfig{}) {
+		cb.SetLegend(chart.Legend)
+	}
+
+	for _, series := range chart.DataSeries {
+		name := series.Name
+		if index := columnIndex(columns, name); index > 0 && firstRow > 1 {
+			colName, _ := excelize.ColumnNumberToName(index)
+			name = fmt.Sprintf("%s!$%s$%d", quoteSheetName(sb.sheetName), colName, firstRow-1)
+		}
+		cb.AddDataSeries(DataSeries{
+			Name:       name,
+			Categories: columnRange(series.Categories, firstRow, lastRow),
+			Values:     columnRange(series.Values, firstRow, lastRow),
+			Color:      series.Color,
+		})
+	}
+
+	switch {
+	case chart.Anchor != "":
+		cb.SetAnchorRange(chart.Anchor)
+	case chart.Position != "":
+		cb.SetPosition(chart.Position)
+	default:
+		// Leave a blank column between the data and the chart
+		cell, _ := excelize.CoordinatesToCellName(len(columns)+2, 1)
+		cb.SetPosition(cell)
+	}
+	return cb.Build()
+}
+
+// decodeSpecDocument decodes a YAML or JSON document into target. YAML is a
+// superset of JSON, so one parser reads both. The document is then decoded
+// through encoding/json, which matches keys to field names
+// case-insensitively without struct tags.
+func decodeSpecDocument(content []byte, target interface{}) error {
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return fmt.Errorf("failed to parse document: %w", err)
+	}
+	if document == nil {
+		return fmt.Errorf("document is empty")
+	}
+	normalized, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(normalized))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(target)
+}
+
+// validateWorkbookSpec checks the references between the parts of a spec
+func validateWorkbookSpec(spec *TemplateConfig) error {
+	if len(spec.Sheets) == 0 {
+		return fmt.Errorf("workbook spec must define at least one sheet")
+	}
+
+	for i, sheet := range spec.Sheets {
+		if sheet.Name == "" {
+			return fmt.Errorf("sheet %d: name cannot be empty", i+1)
+		}
+		hasStyle := func(name string) bool {
+			_, inSheet := sheet.Styles[name]
+			_, inSpec := spec.Styles[name]
+			return inSheet || inSpec
+		}
+		if sheet.HeaderStyle != "" && !hasStyle(sheet.HeaderStyle) {
+			return fmt.Errorf("sheet %s: unknown header style '%s'", sheet.Name, sheet.HeaderStyle)
+		}
+
+		columns := sheetColumns(sheet)
+		for _, column := range columns {
+			if column.Style != "" && !hasStyle(column.Style) {
+				return fmt.Errorf("sheet %s column %s: unknown style '%s'", sheet.Name, column.Header, column.Style)
+			}
+		}
+		for j, validation := range sheet.Validations {
+			switch {
+			case validation.Column == "" && validation.Range == "":
+				return fmt.Errorf("sheet %s validation %d: column or range is required", sheet.Name, j+1)
+			case validation.Column != "" && columnIndex(columns, validation.Column) == 0:
+				return fmt.Errorf("sheet %s validation %d: unknown column '%s'", sheet.Name, j+1, validation.Column)
+			}
+		}
+		for j, chart := range sheet.Charts {
+			if chart.Type == "" {
+				return fmt.Errorf("sheet %s chart %d: type is required", sheet.Name, j+1)
+			}
+			if len(chart.DataSeries) == 0 {
+				return fmt.Errorf("sheet %s chart %d: at least one data series is required", sheet.Name, j+1)
+			}
+		}
+	}
+	return nil
+}
+
+// sheetColumns returns the columns of a sheet, derived from Headers when
+// no columns are defined
+func sheetColumns(sheet SheetTemplate) []ColumnTemplate {
+	if len(sheet.Columns) > 0 {
+		return sheet.Columns
+	}
+	columns := make([]ColumnTemplate, len(sheet.Headers))
+	for i, header := range sheet.Headers {
+		columns[i] = ColumnTemplate{Header: header}
+	}
+	return columns
+}
+
+// columnIndex returns the 1-based position of the column with header, or 0
+func columnIndex(columns []ColumnTemplate, header string) int {
+	for i, column := range columns {
+		if column.Header == header {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// mergeVariables combines variable maps, later maps taking precedence
+func mergeVariables(maps ...map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for _, m := range maps {
+		for key, value := range m {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package excelbuilder_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readZipEntry returns the content of a part inside the saved workbook package
+func readZipEntry(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	for _, f := range reader.File {
+		if f.Name == name {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			defer rc.Close()
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			return string(content)
+		}
+	}
+	t.Fatalf("part %s not found in workbook", name)
+	return ""
+}
+
+func newChartSheet(t *testing.T) (*excelbuilder.WorkbookBuilder, *excelbuilder.SheetBuilder) {
+	t.Helper()
+	wb := excelbuilder.New().NewWorkbook()
+	sheet := wb.AddSheet("Sales")
+	sheet.AddRow().AddCells("Month", "Revenue")
+	sheet.AddRow().AddCells("Jan", 100)
+	sheet.AddRow().AddCells("Feb", 150)
+	return wb, sheet
+}
+
+func TestChartBuilder_WithFormat(t *testing.T) {
+	wb, sheet := newChartSheet(t)
+	disabled := false
+
+	err := sheet.AddChart().
+		SetType("col").
+		SetTitle("Revenue").
+		SetPosition("D2").
+		WithFormat(excelbuilder.ChartFormat{
+			XScale:    1.5,
+			YScale:    0.5,
+			XOffset:   10,
+			YOffset:   5,
+			PrintObj:  &disabled,
+			LockRatio: true,
+			Locked:    &disabled,
+		}).
+		AddDataSeries(excelbuilder.DataSeries{Name: "Revenue", Categories: "Sales!$A$2:$A$3", Values: "Sales!$B$2:$B$3"}).
+		Build()
+	require.NoError(t, err)
+
+	buf, err := wb.Build().WriteToBuffer()
+	require.NoError(t, err)
+	drawing := readZipEntry(t, buf.Bytes(), "xl/drawings/drawing1.xml")
+	assert.Contains(t, drawing, `fPrintsWithSheet="false"`, "PrintObj should be applied")
+	assert.Contains(t, drawing, `fLocksWithSheet="false"`, "Locked should be applied")
+}
+
+func TestChartBuilder_WithFormatScaleOnly(t *testing.T) {
+	wb, sheet := newChartSheet(t)
+
+	err := sheet.AddChart().
+		SetType("col").
+		SetPosition("D2").
+		WithFormat(excelbuilder.ChartFormat{XScale: 1.5}).
+		AddDataSeries(excelbuilder.DataSeries{Name: "Revenue", Categories: "Sales!$A$2:$A$3", Values: "Sales!$B$2:$B$3"}).
+		Build()
+	require.NoError(t, err)
+
+	buf, err := wb.Build().WriteToBuffer()
+	require.NoError(t, err)
+	drawing := readZipEntry(t, buf.Bytes(), "xl/drawings/drawing1.xml")
+	assert.Contains(t, drawing, `fPrintsWithSheet="true"`, "Charts should print unless PrintObj is set")
+}
+
+func TestChartBuilder_SetAnchorRange(t *testing.T) {
+	wb, sheet := newChartSheet(t)
+
+	err := sheet.AddChart().
+		SetType("line").
+		SetAnchorRange("D2:H12").
+		AddDataSeries(excelbuilder.DataSeries{Name: "Revenue", Categories: "Sales!$A$2:$A$3", Values: "Sales!$B$2:$B$3"}).
+		Build()
+	require.NoError(t, err)
+
+	buf, err := wb.Build().WriteToBuffer()
+	require.NoError(t, err)
+	drawing := readZipEntry(t, buf.Bytes(), "xl/drawings/drawing1.xml")
+	assert.Contains(t, drawing, `editAs="twoCell"`, "Anchored charts should resize with cells")
+	assert.Contains(t, drawing, "<xdr:from><xdr:col>3</xdr:col><xdr:colOff>0</xdr:colOff><xdr:row>1</xdr:row>")
+	assert.Contains(t, drawing, "<xdr:to><xdr:col>8</xdr:col><xdr:colOff>0</xdr:colOff><xdr:row>12</xdr:row>")
+}
+
+func TestChartBuilder_SetAnchorRangeIgnoresOffsets(t *testing.T) {
+	wb, sheet := newChartSheet(t)
+
+	err := sheet.AddChart().
+		SetType("line").
+		SetAnchorRange("D2:H12").
+		WithFormat(excelbuilder.ChartFormat{XScale: 2, XOffset: 40, YOffset: 30}).
+		AddDataSeries(excelbuilder.DataSeries{Name: "Revenue", Categories: "Sales!$A$2:$A$3", Values: "Sales!$B$2:$B$3"}).
+		Build()
+	require.NoError(t, err)
+
+	buf, err := wb.Build().WriteToBuffer()
+	require.NoError(t, err)
+	drawing := readZipEntry(t, buf.Bytes(), "xl/drawings/drawing1.xml")
+	assert.Contains(t, drawing, "<xdr:from><xdr:col>3</xdr:col><xdr:colOff>0</xdr:colOff><xdr:row>1</xdr:row><xdr:rowOff>0</xdr:rowOff>")
+	assert.Contains(t, drawing, "<xdr:to><xdr:col>8</xdr:col><xdr:colOff>0</xdr:colOff><xdr:row>12</xdr:row><xdr:rowOff>0</xdr:rowOff>")
+}
+
+func TestChartBuilder_SetAnchorRange_Invalid(t *testing.T) {
+	testCases := []struct {
+		name      string
+		cellRange string
+	}{
+		{"Missing Separator", "D2H12"},
+		{"Reverse Range", "H12:D2"},
+		{"Invalid Cell", "D2:?12"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, sheet := newChartSheet(t)
+			err := sheet.AddChart().
+				SetAnchorRange(tc.cellRange).
+				AddDataSeries(excelbuilder.DataSeries{Name: "Revenue", Values: "Sales!$B$2:$B$3"}).
+				Build()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWorkbookBuilder_AddChartSheet(t *testing.T) {
+	wb, _ := newChartSheet(t)
+
+	err := wb.AddChartSheet("Revenue Chart").
+		SetType("col").
+		SetTitle("Revenue").
+		AddDataSeries(excelbuilder.DataSeries{Name: "Revenue", Categories: "Sales!$A$2:$A$3", Values: "Sales!$B$2:$B$3"}).
+		Build()
+	require.NoError(t, err)
+
+	file := wb.Build()
+	assert.Contains(t, file.GetSheetList(), "Revenue Chart")
+
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+	workbook := readZipEntry(t, buf.Bytes(), "xl/workbook.xml")
+	assert.Contains(t, workbook, `name="Revenue Chart"`)
+	readZipEntry(t, buf.Bytes(), "xl/chartsheets/sheet3.xml")
+}
+
+func TestWorkbookBuilder_AddChartSheet_Validation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		sheetName string
+	}{
+		{"Empty Name", ""},
+		{"Invalid Char", "Chart/1"},
+		{"Existing Sheet", "Sales"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := excelbuilder.New().WithErrorCollection(true)
+			wb := builder.NewWorkbook()
+			wb.AddSheet("Sales").AddRow().AddCells("Jan", 100)
+
+			chart := wb.AddChartSheet(tc.sheetName)
+			assert.NotNil(t, chart, "Builder should never return nil")
+
+			err := chart.
+				AddDataSeries(excelbuilder.DataSeries{Name: "Revenue", Values: "Sales!$B$1:$B$1"}).
+				Build()
+			assert.Error(t, err)
+		})
+	}
+}
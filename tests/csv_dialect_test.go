@@ -0,0 +1,197 @@
+package excelbuilder_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// encodeCSV encodes UTF-8 text with enc
+func encodeCSV(t *testing.T, enc encoding.Encoding, text string) []byte {
+	t.Helper()
+	data, err := enc.NewEncoder().Bytes([]byte(text))
+	require.NoError(t, err)
+	return data
+}
+
+// importCSV streams data into a sheet and returns its raw rows
+func importCSV(t *testing.T, data []byte, options excelbuilder.CSVOptions) [][]string {
+	t.Helper()
+	workbook := excelbuilder.New().NewWorkbook()
+	require.NoError(t, workbook.AddSheetFromCSV("Data", strings.NewReader(string(data)), options))
+	rows, err := workbook.Build().GetRows("Data", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	return rows
+}
+
+func TestAddSheetFromCSV_DetectsEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want [][]string
+	}{
+		{
+			name: "utf-8 with BOM",
+			data: []byte("\xEF\xBB\xBFname,city\nZoë,Zürich\n"),
+			want: [][]string{{"name", "city"}, {"Zoë", "Zürich"}},
+		},
+		{
+			name: "windows-1252",
+			data: encodeCSV(t, charmap.Windows1252, "name,city\nRené,Köln\n“Café”,Genève\n"),
+			want: [][]string{{"name", "city"}, {"René", "Köln"}, {"“Café”", "Genève"}},
+		},
+		{
+			name: "utf-16 with BOM",
+			data: encodeCSV(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), "name,city\nZoë,Zürich\n"),
+			want: [][]string{{"name", "city"}, {"Zoë", "Zürich"}},
+		},
+		{
+			name: "utf-16 big endian without BOM",
+			data: encodeCSV(t, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), "name,city\nZoë,Zürich\n"),
+			want: [][]string{{"name", "city"}, {"Zoë", "Zürich"}},
+		},
+		{
+			name: "shift-jis",
+			data: encodeCSV(t, japanese.ShiftJIS, "名前,都市\n山田,東京\nｶﾀｶﾅ,大阪\n"),
+			want: [][]string{{"名前", "都市"}, {"山田", "東京"}, {"ｶﾀｶﾅ", "大阪"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, importCSV(t, tt.data, excelbuilder.CSVOptions{}))
+		})
+	}
+}
+
+func TestAddSheetFromCSV_ExplicitEncoding(t *testing.T) {
+	// Valid UTF-8 would be detected as such
+	data := encodeCSV(t, charmap.ISO8859_1, "Müller\n")
+	rows := importCSV(t, data, excelbuilder.CSVOptions{Encoding: "iso-8859-1"})
+	assert.Equal(t, [][]string{{"Müller"}}, rows)
+
+	workbook := excelbuilder.New().NewWorkbook()
+	err := workbook.AddSheetFromCSV("Data", strings.NewReader("a\n"), excelbuilder.CSVOptions{Encoding: "klingon"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported CSV encoding")
+}
+
+func TestAddSheetFromCSV_SniffsDelimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		options excelbuilder.CSVOptions
+		want    [][]string
+	}{
+		{
+			name: "semicolon with commas in values",
+			data: "name;amount\n\"Doe, Jane\";1,5\nRoe;2\n",
+			want: [][]string{{"name", "amount"}, {"Doe, Jane", "1,5"}, {"Roe", "2"}},
+		},
+		{
+			name: "tab",
+			data: "a\tb\tc\n1\t2\t3\n",
+			want: [][]string{{"a", "b", "c"}, {"1", "2", "3"}},
+		},
+		{
+			name: "pipe after skipped and comment lines",
+			data: "report, generated today\n# a;b;c\nx|y\n1|2\n",
+			options: excelbuilder.CSVOptions{
+				SkipRows: 1,
+				Comment:  '#',
+			},
+			want: [][]string{{"x", "y"}, {"1", "2"}},
+		},
+		{
+			name: "single column",
+			data: "value\n1\n",
+			want: [][]string{{"value"}, {"1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, importCSV(t, []byte(tt.data), tt.options))
+		})
+	}
+}
+
+func TestAddSheetFromCSV_LocaleNumbers(t *testing.T) {
+	data := "Artikel;Preis;Anteil\nTee;1.234,56;12,5%\nKaffee;7,5;50%\n"
+	rows := importCSV(t, []byte(data), excelbuilder.CSVOptions{HasHeader: true, Locale: "de-DE"})
+	assert.Equal(t, [][]string{{"Artikel", "Preis", "Anteil"}, {"Tee", "1234.56", "0.125"}, {"Kaffee", "7.5", "0.5"}}, rows)
+
+	data = "article;prix\nthé;1\u00a0234,5\ncafé;12 000\n"
+	rows = importCSV(t, []byte(data), excelbuilder.CSVOptions{HasHeader: true, Locale: "fr-FR"})
+	assert.Equal(t, [][]string{{"article", "prix"}, {"thé", "1234.5"}, {"café", "12000"}}, rows)
+
+	// The decimal comma is never the delimiter, even when it is as frequent
+	data = "1,5;2,5\n3,5;4,5\n"
+	rows = importCSV(t, []byte(data), excelbuilder.CSVOptions{Locale: "de-DE"})
+	assert.Equal(t, [][]string{{"1.5", "2.5"}, {"3.5", "4.5"}}, rows)
+
+	workbook := excelbuilder.New().NewWorkbook()
+	err := workbook.AddSheetFromCSV("Data", strings.NewReader("a\n"), excelbuilder.CSVOptions{Locale: "not a locale"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid CSV locale")
+}
+
+func TestImportHelper_FromCSVWithOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.csv")
+	content := encodeCSV(t, charmap.Windows1252, "Exportiert am 01.02.2024\nKunde;PLZ;Umsatz;Notiz\nMüller;01067;1.234,56;ok\nSchäfer;80331;-12;1.5.2024\n")
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+
+	file := excelbuilder.NewImportHelper().
+		FromCSVWithOptions(path, excelbuilder.CSVOptions{SkipRows: 1, Locale: "de"}).
+		ToExcel().
+		Build()
+
+	rows, err := file.GetRows("CustomCSV", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Kunde", "PLZ", "Umsatz", "Notiz"},
+		{"Müller", "01067", "1234.56", "ok"},
+		{"Schäfer", "80331", "-12", "1.5.2024"},
+	}, rows)
+
+	for cell, want := range map[string]excelize.CellType{"B2": excelize.CellTypeSharedString, "B3": excelize.CellTypeUnset, "C2": excelize.CellTypeUnset, "D3": excelize.CellTypeSharedString} {
+		cellType, err := file.GetCellType("CustomCSV", cell)
+		require.NoError(t, err)
+		assert.Equal(t, want, cellType, cell)
+	}
+}
+
+func TestImportHelper_FromCSVWithOptionsErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.csv")
+	require.NoError(t, os.WriteFile(path, []byte("a;b\n"), 0o600))
+
+	tests := []struct {
+		name    string
+		options excelbuilder.CSVOptions
+		want    string
+	}{
+		{name: "locale", options: excelbuilder.CSVOptions{Locale: "not a locale"}, want: "invalid CSV locale"},
+		{name: "encoding", options: excelbuilder.CSVOptions{Encoding: "no-such-encoding"}, want: "unsupported CSV encoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				recovered := recover()
+				require.NotNil(t, recovered)
+				assert.Contains(t, recovered, "failed to import CSV file")
+				assert.Contains(t, recovered, tt.want)
+			}()
+			excelbuilder.NewImportHelper().FromCSVWithOptions(path, tt.options)
+		})
+	}
+}
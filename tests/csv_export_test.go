@@ -0,0 +1,123 @@
+package excelbuilder_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportWorkbook(t *testing.T) *excelbuilder.WorkbookBuilder {
+	t.Helper()
+	workbook := excelbuilder.New().NewWorkbook()
+
+	sales := workbook.AddSheet("Sales")
+	sales.AddRow().AddCells("Item", "Price", "Qty", "Total")
+	row := sales.AddRow()
+	row.AddCells("Tea, green")
+	row.AddCell(1234.5).WithNumberFormat("#,##0.00")
+	row.AddCell(2)
+	row.AddCell(nil).WithFormula("B2*C2")
+	sales.AddRow()
+	sales.AddRow().AddCells("Total")
+	sales.SetRowHeight(8, 30) // Formatting only, not exported
+
+	workbook.AddSheet("Notes").AddRow().AddCells("note")
+	workbook.AddChartSheet("Chart").
+		SetType("col").
+		AddDataSeries(excelbuilder.DataSeries{Name: "Price", Categories: "Sales!$A$2:$A$2", Values: "Sales!$B$2:$B$2"}).
+		Build()
+	workbook.SetActiveSheet("Sales")
+	return workbook
+}
+
+func TestExportHelper_WriteCSV(t *testing.T) {
+	export := excelbuilder.NewExportHelper().FromExcel(newExportWorkbook(t))
+
+	tests := []struct {
+		name    string
+		options excelbuilder.CSVOptions
+		want    string
+	}{
+		{
+			name:    "displayed values",
+			options: excelbuilder.CSVOptions{},
+			want:    "Item,Price,Qty,Total\n\"Tea, green\",\"1,234.50\",2,\n\nTotal\n",
+		},
+		{
+			name:    "raw values and formulas",
+			options: excelbuilder.CSVOptions{RawValues: true, Formulas: true},
+			want:    "Item,Price,Qty,Total\n\"Tea, green\",1234.5,2,=B2*C2\n\nTotal\n",
+		},
+		{
+			name:    "delimiter, BOM and CRLF",
+			options: excelbuilder.CSVOptions{Delimiter: ";", BOM: true, UseCRLF: true, RawValues: true},
+			want:    "\xEF\xBB\xBFItem;Price;Qty;Total\r\nTea, green;1234.5;2;\r\n\r\nTotal\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, export.WriteCSV(&buf, "Sales", tt.options))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestExportHelper_ToCSVExportsActiveSheet(t *testing.T) {
+	workbook := newExportWorkbook(t)
+	workbook.SetActiveSheet("Notes")
+	path := filepath.Join(t.TempDir(), "notes.csv")
+
+	require.NoError(t, excelbuilder.NewExportHelper().FromExcel(workbook).ToCSV(path))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "note\n", string(content))
+}
+
+func TestExportHelper_WriteCSVZip(t *testing.T) {
+	export := excelbuilder.NewExportHelper().FromExcel(newExportWorkbook(t))
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteCSVZip(&buf, excelbuilder.CSVOptions{RawValues: true}))
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	var names []string
+	for _, entry := range reader.File {
+		rc, err := entry.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		names = append(names, entry.Name)
+		contents[entry.Name] = string(data)
+	}
+
+	assert.Equal(t, []string{"Sheet1.csv", "Sales.csv", "Notes.csv"}, names)
+	assert.Equal(t, "", contents["Sheet1.csv"])
+	assert.Equal(t, "note\n", contents["Notes.csv"])
+	assert.Contains(t, contents["Sales.csv"], "\"Tea, green\",1234.5,2,")
+}
+
+func TestExportHelper_CSVErrors(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, excelbuilder.NewExportHelper().WriteCSV(&buf, "Sales", excelbuilder.CSVOptions{}))
+	assert.Error(t, excelbuilder.NewExportHelper().ToCSV(filepath.Join(t.TempDir(), "out.csv")))
+	assert.Error(t, excelbuilder.NewExportHelper().WriteCSVZip(&buf, excelbuilder.CSVOptions{}))
+
+	export := excelbuilder.NewExportHelper().FromExcel(newExportWorkbook(t))
+	err := export.WriteCSV(&buf, "Missing", excelbuilder.CSVOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+
+	err = export.ToCSV(filepath.Join(t.TempDir(), "missing", "out.csv"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,145 @@
+package excelbuilder_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+const ordersCSV = `# exported orders
+ID,Zip,Qty,Price,Paid,Ordered,Amount,Note
+1,00501,2,1.5,true,2024-01-02,"$1,200.00",first
+2,10001,3,2,false,2024-02-03,$15.50,
+3,02134,4,2.25,TRUE,2024-03-04,$7,late
+`
+
+// cellNumFmt returns the built-in number format of the style of a cell
+func cellNumFmt(t *testing.T, file *excelize.File, sheet, cell string) (int, string) {
+	t.Helper()
+	styleID, err := file.GetCellStyle(sheet, cell)
+	require.NoError(t, err)
+	style, err := file.GetStyle(styleID)
+	require.NoError(t, err)
+	custom := ""
+	if style.CustomNumFmt != nil {
+		custom = *style.CustomNumFmt
+	}
+	return style.NumFmt, custom
+}
+
+func TestWorkbookBuilder_AddSheetFromCSV(t *testing.T) {
+	workbook := excelbuilder.New().NewWorkbook()
+	err := workbook.AddSheetFromCSV("Orders", strings.NewReader(ordersCSV), excelbuilder.CSVOptions{
+		SkipRows:  1,
+		HasHeader: true,
+	})
+	require.NoError(t, err)
+	file := workbook.Build()
+
+	rows, err := file.GetRows("Orders", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, []string{"ID", "Zip", "Qty", "Price", "Paid", "Ordered", "Amount", "Note"}, rows[0])
+	assert.Equal(t, "00501", rows[1][1])
+	assert.Equal(t, "02134", rows[3][1])
+	assert.Equal(t, "1200", rows[1][6])
+
+	tests := []struct {
+		cell     string
+		cellType excelize.CellType
+		numFmt   int
+		custom   string
+	}{
+		{cell: "A2", cellType: excelize.CellTypeUnset, numFmt: 1},
+		{cell: "B2", cellType: excelize.CellTypeInlineString, numFmt: 49},
+		{cell: "D3", cellType: excelize.CellTypeUnset},
+		{cell: "E2", cellType: excelize.CellTypeBool},
+		{cell: "E4", cellType: excelize.CellTypeBool},
+		{cell: "F2", cellType: excelize.CellTypeUnset, custom: "yyyy-mm-dd"},
+		{cell: "G3", cellType: excelize.CellTypeUnset, custom: "$#,##0.00"},
+		{cell: "H2", cellType: excelize.CellTypeInlineString, numFmt: 49},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cell, func(t *testing.T) {
+			cellType, err := file.GetCellType("Orders", tt.cell)
+			require.NoError(t, err)
+			assert.Equal(t, tt.cellType, cellType)
+			numFmt, custom := cellNumFmt(t, file, "Orders", tt.cell)
+			assert.Equal(t, tt.numFmt, numFmt)
+			assert.Equal(t, tt.custom, custom)
+		})
+	}
+
+	value, err := file.GetCellValue("Orders", "F3")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-03", value)
+	value, err = file.GetCellValue("Orders", "G2")
+	require.NoError(t, err)
+	assert.Equal(t, "$1,200.00", value)
+}
+
+func TestWorkbookBuilder_AddSheetFromCSVLocksSampledTypes(t *testing.T) {
+	data := "1,1\n2,2.5\n3,3\nn/a,4\n"
+	workbook := excelbuilder.New().NewWorkbook()
+	require.NoError(t, workbook.AddSheetFromCSV("Data", strings.NewReader(data), excelbuilder.CSVOptions{SampleRows: 2}))
+	file := workbook.Build()
+
+	// Column A was locked as integer; the late text value is kept as is
+	cellType, err := file.GetCellType("Data", "A4")
+	require.NoError(t, err)
+	assert.Equal(t, excelize.CellTypeInlineString, cellType)
+	value, err := file.GetCellValue("Data", "A4")
+	require.NoError(t, err)
+	assert.Equal(t, "n/a", value)
+	numFmt, _ := cellNumFmt(t, file, "Data", "A3")
+	assert.Equal(t, 1, numFmt)
+
+	// Integers and floats in one column make it float
+	value, err = file.GetCellValue("Data", "B2")
+	require.NoError(t, err)
+	assert.Equal(t, "2.5", value)
+	numFmt, _ = cellNumFmt(t, file, "Data", "B1")
+	assert.Equal(t, 0, numFmt)
+}
+
+func TestImportHelper_StreamCSVToExcel(t *testing.T) {
+	data := "sku;qty\n0042;5\n0043;7\n"
+	var buf bytes.Buffer
+	err := excelbuilder.NewImportHelper().StreamCSVToExcel(strings.NewReader(data), &buf, excelbuilder.CSVOptions{Delimiter: ";", HasHeader: true})
+	require.NoError(t, err)
+
+	file, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ImportedData"}, file.GetSheetList())
+	rows, err := file.GetRows("ImportedData")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"sku", "qty"}, {"0042", "5"}, {"0043", "7"}}, rows)
+}
+
+func TestWorkbookBuilder_AddSheetFromCSVErrors(t *testing.T) {
+	workbook := excelbuilder.New().NewWorkbook()
+	assert.Error(t, workbook.AddSheetFromCSV("Sheet1", strings.NewReader("a\n"), excelbuilder.CSVOptions{}))
+	assert.Error(t, workbook.AddSheetFromCSV("Bad/Name", strings.NewReader("a\n"), excelbuilder.CSVOptions{}))
+
+	err := workbook.AddSheetFromCSV("Broken", strings.NewReader("a,\"b\n"), excelbuilder.CSVOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read CSV")
+	assert.Equal(t, []string{"Sheet1"}, workbook.Build().GetSheetList(), "Failed imports should not leave a sheet")
+}
+
+func TestWorkbookBuilder_AddSheetFromCSVRetry(t *testing.T) {
+	workbook := excelbuilder.New().NewWorkbook()
+	err := workbook.AddSheetFromCSV("Data", strings.NewReader("amount\n1\n"), excelbuilder.CSVOptions{Locale: "!!bad"})
+	require.Error(t, err)
+	assert.Equal(t, []string{"Sheet1"}, workbook.Build().GetSheetList())
+
+	require.NoError(t, workbook.AddSheetFromCSV("Data", strings.NewReader("amount\n1\n"), excelbuilder.CSVOptions{HasHeader: true}))
+	rows, err := workbook.Build().GetRows("Data")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"amount"}, {"1"}}, rows)
+}
@@ -0,0 +1,137 @@
+package excelbuilder_test
+
+import (
+	"testing"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func salesRecords() []map[string]interface{} {
+	return []map[string]interface{}{
+		{"Region": "North", "Product": "Laptop", "Quarter": "Q1", "Sales": 100},
+		{"Region": "North", "Product": "Laptop", "Quarter": "Q2", "Sales": 150},
+		{"Region": "North", "Product": "Monitor", "Quarter": "Q1", "Sales": 50},
+		{"Region": "South", "Product": "Laptop", "Quarter": "Q1", "Sales": 200},
+		{"Region": "South", "Product": "Laptop", "Quarter": "Q1", "Sales": 25.5},
+	}
+}
+
+func TestDataTransformer_PivotData_Crosstab(t *testing.T) {
+	rows, err := excelbuilder.NewDataTransformer().PivotData(salesRecords(), excelbuilder.PivotConfig{
+		RowFields:    []string{"Region"},
+		ColumnFields: []string{"Quarter"},
+		ValueFields:  []string{"Sales"},
+		Aggregation:  "sum",
+	})
+	require.NoError(t, err)
+
+	expected := [][]interface{}{
+		{"Region", "Q1", "Q2", "Grand Total"},
+		{"North", 150.0, 150.0, 300.0},
+		{"South", 225.5, nil, 225.5},
+		{"Grand Total", 375.5, 150.0, 525.5},
+	}
+	assert.Equal(t, expected, rows)
+}
+
+func TestDataTransformer_PivotData_Subtotals(t *testing.T) {
+	rows, err := excelbuilder.NewDataTransformer().PivotData(salesRecords(), excelbuilder.PivotConfig{
+		RowFields:   []string{"Region", "Product"},
+		ValueFields: []string{"Sales"},
+		Aggregation: "count",
+	})
+	require.NoError(t, err)
+
+	expected := [][]interface{}{
+		{"Region", "Product", "count of Sales"},
+		{"North", "Laptop", 2},
+		{"North", "Monitor", 1},
+		{"North Total", nil, 3},
+		{"South", "Laptop", 2},
+		{"South Total", nil, 2},
+		{"Grand Total", nil, 5},
+	}
+	assert.Equal(t, expected, rows)
+}
+
+func TestDataTransformer_PivotData_Aggregations(t *testing.T) {
+	testCases := []struct {
+		name        string
+		aggregation string
+		expected    interface{}
+	}{
+		{"Sum", "sum", 525.5},
+		{"Default Sum", "", 525.5},
+		{"Count", "count", 5},
+		{"Average", "avg", 105.1},
+		{"Min", "min", 25.5},
+		{"Max", "max", 200.0},
+		{"Distinct Count", "distinct", 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := excelbuilder.NewDataTransformer().PivotData(salesRecords(), excelbuilder.PivotConfig{
+				ValueFields: []string{"Sales"},
+				Aggregation: tc.aggregation,
+			})
+			require.NoError(t, err)
+			require.Len(t, rows, 2, "Without row fields only the grand total row is produced")
+			assert.InDelta(t, tc.expected, rows[1][1], 1e-9)
+		})
+	}
+}
+
+func TestDataTransformer_PivotData_CustomAggregation(t *testing.T) {
+	rows, err := excelbuilder.NewDataTransformer().PivotData(salesRecords(), excelbuilder.PivotConfig{
+		RowFields:   []string{"Region"},
+		ValueFields: []string{"Product"},
+		Aggregation: "custom",
+		CustomAggregation: func(values []interface{}) interface{} {
+			return values[0]
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"North", "Laptop"}, rows[1])
+}
+
+func TestDataTransformer_PivotData_Errors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config excelbuilder.PivotConfig
+	}{
+		{"No Value Fields", excelbuilder.PivotConfig{RowFields: []string{"Region"}}},
+		{"Unknown Aggregation", excelbuilder.PivotConfig{ValueFields: []string{"Sales"}, Aggregation: "median"}},
+		{"Custom Without Func", excelbuilder.PivotConfig{ValueFields: []string{"Sales"}, Aggregation: "custom"}},
+		{"Non Numeric Sum", excelbuilder.PivotConfig{ValueFields: []string{"Product"}, Aggregation: "sum"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := excelbuilder.NewDataTransformer().PivotData(salesRecords(), tc.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExcelBuilder_TransformDataToPivot(t *testing.T) {
+	builder := excelbuilder.New().WithErrorCollection(true)
+	file := builder.TransformDataToPivot(salesRecords(), excelbuilder.PivotConfig{
+		RowFields:   []string{"Region", "Product"},
+		ValueFields: []string{"Sales"},
+	}, "Summary").Build()
+	assert.False(t, builder.HasErrors())
+
+	rows, err := file.GetRows("Summary")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Region", "Product", "sum of Sales"}, rows[0])
+	assert.Equal(t, []string{"Grand Total", "", "525.5"}, rows[len(rows)-1])
+
+	headerStyle, err := file.GetCellStyle("Summary", "A1")
+	require.NoError(t, err)
+	dataStyle, err := file.GetCellStyle("Summary", "A2")
+	require.NoError(t, err)
+	assert.NotEqual(t, headerStyle, dataStyle, "Header row should be styled")
+}
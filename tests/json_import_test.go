@@ -0,0 +1,120 @@
+package excelbuilder_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ordersJSON = `{
+	"exported": "2024-05-01",
+	"orders": [
+		{"number": "A-1", "id": 7, "customer": {"name": "Ann", "city": "Oslo"}, "tags": ["new", "vip"],
+		 "items": [{"sku": "tea", "qty": 2}, {"sku": "cup", "qty": 1, "notes": [{"text": "gift"}]}]},
+		{"number": "A-2", "customer": {"name": "Bob"}, "discount": 0.1, "items": [], "tags": []},
+		{"number": "A-3", "items": [{"qty": 5, "sku": "pot"}]}
+	]
+}`
+
+// writeJSONFile writes content to a JSON file in a temporary directory
+func writeJSONFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestImportHelper_FromJSONKeepsDocumentOrder(t *testing.T) {
+	file := excelbuilder.NewImportHelper().FromJSON(writeJSONFile(t, ordersJSON)).ToExcel().Build()
+
+	rows, err := file.GetRows("JSONData")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"number", "id", "customer.name", "customer.city", "tags", "discount"},
+		{"A-1", "7", "Ann", "Oslo", "new, vip"},
+		{"A-2", "", "Bob", "", "", "0.1"},
+		{"A-3"},
+	}, rows)
+
+	rows, err = file.GetRows("items")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"parent_id", "sku", "qty"},
+		{"7", "tea", "2"},
+		{"7", "cup", "1"},
+		{"3", "pot", "5"},
+	}, rows)
+
+	rows, err = file.GetRows("notes")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"parent_id", "text"}, {"2", "gift"}}, rows)
+
+	assert.Equal(t, []string{"Sheet1", "JSONData", "items", "notes"}, file.GetSheetList())
+}
+
+func TestImportHelper_FromJSONSingleObject(t *testing.T) {
+	content := `{"zeta": 1, "alpha": "a", "meta": {"ok": true}, "zeta": 2}`
+	file := excelbuilder.NewImportHelper().FromJSON(writeJSONFile(t, content)).ToExcel().Build()
+
+	rows, err := file.GetRows("JSONData")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"zeta", "alpha", "meta.ok"}, {"2", "a", "TRUE"}}, rows)
+}
+
+func TestImportHelper_FromJSONFlattenKeepsOrder(t *testing.T) {
+	content := `[{"b": 1, "a": {"z": 2, "y": 3}}, {"c": 4, "b": 5}]`
+	file := excelbuilder.NewImportHelper().
+		FromJSON(writeJSONFile(t, content)).
+		WithFlattenOptions(excelbuilder.FlattenOptions{Separator: "_"}).
+		ToExcel().
+		Build()
+
+	rows, err := file.GetRows("JSONData")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"b", "a_z", "a_y", "c"}, {"1", "2", "3"}, {"5", "", "", "4"}}, rows)
+}
+
+func TestImportHelper_ChildSheetNames(t *testing.T) {
+	content := `[{"id": "x", "Sheet1": [{"v": 1}], "jsondata": [{"v": 2}], "a/very/long/nested/array/of/objects/name": [{"v": 3}]}]`
+	file := excelbuilder.NewImportHelper().FromJSON(writeJSONFile(t, content)).ToExcel().Build()
+
+	assert.Equal(t, []string{"Sheet1", "JSONData", "Sheet1 (2)", "jsondata (2)", "a_very_long_nested_array_of_obj"}, file.GetSheetList())
+	rows, err := file.GetRows("jsondata (2)")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"parent_id", "v"}, {"x", "2"}}, rows)
+}
+
+func TestImportHelper_ChildParentIDField(t *testing.T) {
+	content := `[{"id": 7, "lines": [{"sku": "a", "parent_id": 99}]}]`
+	file := excelbuilder.NewImportHelper().FromJSON(writeJSONFile(t, content)).ToExcel().Build()
+
+	rows, err := file.GetRows("lines")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"parent_id", "sku", "lines.parent_id"}, {"7", "a", "99"}}, rows)
+}
+
+func TestExcelBuilder_ConvertJSONToWorkbook(t *testing.T) {
+	data := map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"name": "Ann", "age": 31.0},
+			map[string]interface{}{"name": "Bob", "email": "bob@example.com", "pets": []interface{}{
+				map[string]interface{}{"kind": "cat"},
+			}},
+			"not an object",
+		},
+	}
+
+	for i := 0; i < 5; i++ {
+		file := excelbuilder.New().ConvertJSONToWorkbook(data).Build()
+		rows, err := file.GetRows("Sheet1")
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"age", "name", "email"}, {"31", "Ann"}, {"", "Bob", "bob@example.com"}}, rows)
+		rows, err = file.GetRows("pets")
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"parent_id", "kind"}, {"2", "cat"}}, rows)
+	}
+}
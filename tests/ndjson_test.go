@@ -0,0 +1,154 @@
+package excelbuilder_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+const eventsNDJSON = `{"event": "login", "user": {"id": 1, "name": "ann"}}
+
+{"event": "purchase", "user": {"id": 2}, "total": 9.5, "lines": [{"sku": "tea", "qty": 2}, {"sku": "cup"}]}
+{"user": {"name": "bob"}, "event": "logout", "tags": ["web", "eu"]}
+`
+
+func TestImportHelper_FromNDJSON(t *testing.T) {
+	importer, err := excelbuilder.NewImportHelper().FromNDJSON(strings.NewReader(eventsNDJSON))
+	require.NoError(t, err)
+	file := importer.ToExcel().Build()
+
+	rows, err := file.GetRows("NDJSONData")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"event", "user.id", "user.name", "total", "tags"},
+		{"login", "1", "ann"},
+		{"purchase", "2", "", "9.5"},
+		{"logout", "", "bob", "", "web, eu"},
+	}, rows)
+
+	rows, err = file.GetRows("lines")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"parent_id", "sku", "qty"}, {"2", "tea", "2"}, {"2", "cup"}}, rows)
+}
+
+func TestImportHelper_FromNDJSONManyRecords(t *testing.T) {
+	var input strings.Builder
+	for i := 1; i <= 500; i++ {
+		fmt.Fprintf(&input, "{\"n\": %d}\n", i)
+	}
+	// The last line may lack a line break
+	input.WriteString(`{"n": 501, "last": true}`)
+
+	importer, err := excelbuilder.NewImportHelper().FromNDJSON(strings.NewReader(input.String()))
+	require.NoError(t, err)
+	file := importer.ToExcel().Build()
+	rows, err := file.GetRows("NDJSONData")
+	require.NoError(t, err)
+	require.Len(t, rows, 502)
+	assert.Equal(t, []string{"n", "last"}, rows[0])
+	assert.Equal(t, []string{"501", "TRUE"}, rows[501])
+}
+
+func TestImportHelper_FromNDJSONInvalidLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "malformed", input: "{\"a\": 1}\n\n{\"a\": \n", want: "line 3"},
+		{name: "not an object", input: "[1, 2]\n", want: "record is not an object"},
+		{name: "two records", input: "{\"a\": 1} {\"a\": 2}\n", want: "unexpected data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer, err := excelbuilder.NewImportHelper().FromNDJSON(strings.NewReader(tt.input))
+			assert.Nil(t, importer)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestWorkbookBuilder_AddSheetFromNDJSON(t *testing.T) {
+	workbook := excelbuilder.New().NewWorkbook()
+	require.NoError(t, workbook.AddSheetFromNDJSON("Events", strings.NewReader(eventsNDJSON)))
+	rows, err := workbook.Build().GetRows("Events")
+	require.NoError(t, err)
+	assert.Len(t, rows, 4)
+
+	err = workbook.AddSheetFromNDJSON("Events", strings.NewReader("{}\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+}
+
+func TestWorkbookBuilder_AddSheetFromNDJSONInvalidLine(t *testing.T) {
+	workbook := excelbuilder.New().NewWorkbook()
+	err := workbook.AddSheetFromNDJSON("Data", strings.NewReader("{\"a\": 1}\n{\"a\": \n{\"a\": 3}\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid NDJSON on line 2")
+
+	// The records before the malformed line are kept
+	rows, err := workbook.Build().GetRows("Data")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a"}, {"1"}}, rows)
+}
+
+func TestImportHelper_FromNDJSONLargeIntegers(t *testing.T) {
+	input := "{\"id\": 9007199254740993, \"lines\": [{\"qty\": 1}]}\n{\"id\": 2, \"rate\": 0.1}\n"
+	importer, err := excelbuilder.NewImportHelper().FromNDJSON(strings.NewReader(input))
+	require.NoError(t, err)
+	file := importer.ToExcel().Build()
+
+	id, err := file.GetCellValue("NDJSONData", "A2", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.Equal(t, "9007199254740993", id)
+	parentID, err := file.GetCellValue("lines", "A2", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.Equal(t, "9007199254740993", parentID)
+	rate, err := file.GetCellValue("NDJSONData", "B3", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.Equal(t, "0.1", rate)
+}
+
+func TestExportHelper_ToNDJSON(t *testing.T) {
+	workbook := excelbuilder.New().NewWorkbook()
+	sheet := workbook.AddSheet("People")
+	sheet.AddRow().AddCells("name", "", "score", "active", "joined")
+	sheet.AddRow().AddCells("Ann <admin>", "x", 12.5, true)
+	sheet.AddRow()
+	bob := sheet.AddRow().AddCells("Bob", nil, 7, false)
+	bob.AddCell(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)).WithStyle(excelbuilder.StyleConfig{NumberFormat: "yyyy-mm-dd"})
+
+	var buf bytes.Buffer
+	require.NoError(t, excelbuilder.NewExportHelper().FromExcel(workbook).ToNDJSON(&buf, "People"))
+	assert.Equal(t, `{"name":"Ann <admin>","B":"x","score":12.5,"active":true}`+"\n"+
+		`{"name":"Bob","score":7,"active":false,"joined":"2024-03-01"}`+"\n", buf.String())
+}
+
+func TestNDJSON_RoundTrip(t *testing.T) {
+	input := "{\"id\":\"a\",\"city\":\"Oslo\",\"visits\":9007199254740993}\n{\"id\":\"b\",\"zip\":\"0150\",\"rate\":0.25,\"vip\":true}\n"
+	importer, err := excelbuilder.NewImportHelper().FromNDJSON(strings.NewReader(input))
+	require.NoError(t, err)
+	workbook := importer.ToExcel()
+
+	var buf bytes.Buffer
+	require.NoError(t, excelbuilder.NewExportHelper().FromExcel(workbook).ToNDJSON(&buf, "NDJSONData"))
+	assert.Equal(t, input, buf.String())
+}
+
+func TestExportHelper_ToNDJSONErrors(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, excelbuilder.NewExportHelper().ToNDJSON(&buf, "Sheet1"))
+
+	err := excelbuilder.NewExportHelper().FromExcel(excelbuilder.New().NewWorkbook()).ToNDJSON(&buf, "Missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+}
@@ -0,0 +1,114 @@
+package excelbuilder_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// newLedgerFile saves a ledger workbook with a header, two entries and a
+// formatted but empty row below them
+func newLedgerFile(t *testing.T) string {
+	t.Helper()
+	file := excelize.NewFile()
+	require.NoError(t, file.SetSheetName("Sheet1", "Ledger"))
+	require.NoError(t, file.SetSheetRow("Ledger", "A1", &[]interface{}{"Date", "Amount"}))
+	require.NoError(t, file.SetSheetRow("Ledger", "A2", &[]interface{}{"2024-01-01", 100}))
+	require.NoError(t, file.SetCellFormula("Ledger", "B3", "B2*2"))
+	style, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	require.NoError(t, err)
+	require.NoError(t, file.SetCellStyle("Ledger", "A5", "B5", style))
+
+	path := filepath.Join(t.TempDir(), "ledger.xlsx")
+	require.NoError(t, file.SaveAs(path))
+	return path
+}
+
+func TestOpen_AppendsAfterLastUsedRow(t *testing.T) {
+	path := newLedgerFile(t)
+
+	workbook, err := excelbuilder.Open(path)
+	require.NoError(t, err)
+	sheet := workbook.GetSheet("Ledger")
+	assert.Equal(t, 3, sheet.GetCurrentRow())
+
+	sheet.AddRow().AddCells("2024-01-02", 50)
+	require.NoError(t, workbook.Build().Save())
+
+	reopened, err := excelize.OpenFile(path)
+	require.NoError(t, err)
+	value, err := reopened.GetCellValue("Ledger", "A4")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-02", value)
+	formula, err := reopened.GetCellFormula("Ledger", "B3")
+	require.NoError(t, err)
+	assert.Equal(t, "B2*2", formula)
+}
+
+func TestOpenReader_GetSheetContinuesExistingSheet(t *testing.T) {
+	file := excelize.NewFile()
+	require.NoError(t, file.SetSheetRow("Sheet1", "A1", &[]interface{}{"a"}))
+	require.NoError(t, file.SetSheetRow("Sheet1", "A2", &[]interface{}{"b"}))
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+
+	workbook, err := excelbuilder.OpenReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	workbook.GetSheet("Sheet1").AddRow().AddCell("c")
+	workbook.AddSheet("Summary").AddRow().AddCell("total")
+
+	rows, err := workbook.Build().GetRows("Sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a"}, {"b"}, {"c"}}, rows)
+	rows, err = workbook.Build().GetRows("Summary")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"total"}}, rows)
+}
+
+func TestOpenReader_AddSheetContinuesExistingSheet(t *testing.T) {
+	file := excelize.NewFile()
+	require.NoError(t, file.SetSheetRow("Sheet1", "A1", &[]interface{}{"old", "kept"}))
+	require.NoError(t, file.SetSheetRow("Sheet1", "A2", &[]interface{}{"old"}))
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+
+	workbook, err := excelbuilder.OpenReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	sheet := workbook.AddSheet("Sheet1")
+	assert.Equal(t, 2, sheet.GetCurrentRow())
+	sheet.AddRow().AddCell("new")
+
+	rows, err := workbook.Build().GetRows("Sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"old", "kept"}, {"old"}, {"new"}}, rows, "Existing rows should be kept")
+}
+
+func TestOpen_GetSheetMissing(t *testing.T) {
+	builder := excelbuilder.New().WithErrorCollection(true)
+	workbook, err := builder.OpenWorkbook(newLedgerFile(t))
+	require.NoError(t, err)
+
+	sheet := workbook.GetSheet("Missing")
+	sheet.AddRow().AddCell("lost")
+
+	errs := builder.GetCollectedErrors()
+	require.NotEmpty(t, errs)
+	assert.Contains(t, errs[0].Error(), "sheet 'Missing' does not exist")
+	rows, err := workbook.Build().GetRows("Ledger")
+	require.NoError(t, err)
+	assert.Len(t, rows, 3)
+}
+
+func TestOpen_Errors(t *testing.T) {
+	_, err := excelbuilder.Open(filepath.Join(t.TempDir(), "missing.xlsx"))
+	assert.Error(t, err)
+
+	_, err = excelbuilder.OpenReader(strings.NewReader("not a workbook"))
+	assert.Error(t, err)
+}
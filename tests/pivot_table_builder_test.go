@@ -1,10 +1,13 @@
 package excelbuilder_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TODO: This test is disabled due to a bug/inconsistency in the underlying excelize
@@ -47,3 +50,590 @@ func _TestPivotTableBuilder_BuildSuccessfully(t *testing.T) {
 	// or the underlying excelize library during the AddPivotTable call.
 	assert.NoError(t, err, "Building pivot table should not produce an error")
 }
+
+func newPivotSourceWorkbook() *excelbuilder.WorkbookBuilder {
+	wb := excelbuilder.New().NewWorkbook()
+	raw := wb.AddSheet("Raw Data")
+	raw.AddRow().AddCells("Product", "Region", "Sales")
+	raw.AddRow().AddCells("Laptop", "North", 1000)
+	raw.AddRow().AddCells("Monitor", "North", 500)
+	raw.AddRow().AddCells("Laptop", "South", 1500)
+	raw.AddRow().AddCells("Mouse", "East", 50)
+	return wb
+}
+
+func TestPivotTableBuilder_FieldOptions(t *testing.T) {
+	wb := newPivotSourceWorkbook()
+	pivotSheet := wb.AddSheet("Pivot Report")
+
+	err := pivotSheet.NewPivotTable("Pivot Report", "Raw Data!A1:C5").
+		AddRowFieldWithOptions("Region", excelbuilder.PivotFieldOptions{
+			Sort:        "descending",
+			HiddenItems: []string{"East"},
+		}).
+		AddColumnFieldWithOptions("Product", excelbuilder.PivotFieldOptions{Sort: "ascending"}).
+		AddValueFieldWithOptions("Sales", "sum", excelbuilder.PivotValueFieldOptions{
+			DisplayName:  "Share of Sales",
+			NumberFormat: "0.0%",
+			ShowValuesAs: "percentOfTotal",
+		}).
+		Build()
+	require.NoError(t, err)
+
+	buf, err := wb.Build().WriteToBuffer()
+	require.NoError(t, err)
+	table := readZipEntry(t, buf.Bytes(), "xl/pivotTables/pivotTable1.xml")
+	cache := readZipEntry(t, buf.Bytes(), "xl/pivotCache/pivotCacheDefinition1.xml")
+
+	assert.Contains(t, table, `<pivotField name="Region" axis="axisRow" compact="true" outline="true" showAll="false" defaultSubtotal="true" sortType="descending">`)
+	assert.Contains(t, table, `<pivotField name="Product" axis="axisCol" compact="true" outline="true" showAll="false" defaultSubtotal="true" sortType="ascending">`)
+	assert.Contains(t, table, `<items count="4"><item x="0"></item><item x="1"></item><item h="1" x="2"></item><item t="default"></item></items>`)
+	assert.Contains(t, cache, `<cacheField name="Region" numFmtId="0"><sharedItems count="3"><s v="North"></s><s v="South"></s><s v="East"></s></sharedItems></cacheField>`)
+	assert.Regexp(t, `<dataField name="Share of Sales" fld="2" subtotal="sum" showDataAs="percentOfTotal" numFmtId="\d+">`, table)
+}
+
+func TestPivotTableBuilder_FieldOptions_TwoPivots(t *testing.T) {
+	wb := newPivotSourceWorkbook()
+	pivotSheet := wb.AddSheet("Pivot Report")
+
+	err := pivotSheet.NewPivotTable("Pivot Report", "Raw Data!A1:C5").
+		SetName("ByRegion").
+		AddRowFieldWithOptions("Region", excelbuilder.PivotFieldOptions{Sort: "ascending"}).
+		AddValueField("Sales", "sum").
+		Build()
+	require.NoError(t, err)
+	err = pivotSheet.NewPivotTable("Pivot Report", "Raw Data!A1:C5").
+		SetName("ByProduct").
+		SetTargetCell("J1").
+		AddRowFieldWithOptions("Product", excelbuilder.PivotFieldOptions{Sort: "descending", HiddenItems: []string{"Mouse"}}).
+		AddValueField("Sales", "sum").
+		Build()
+	require.NoError(t, err)
+
+	buf, err := wb.Build().WriteToBuffer()
+	require.NoError(t, err)
+	first := readZipEntry(t, buf.Bytes(), "xl/pivotTables/pivotTable1.xml")
+	second := readZipEntry(t, buf.Bytes(), "xl/pivotTables/pivotTable2.xml")
+	assert.Contains(t, first, `name="ByRegion"`)
+	assert.Contains(t, first, `<pivotField name="Region" axis="axisRow" compact="true" outline="true" showAll="false" defaultSubtotal="true" sortType="ascending">`)
+	assert.NotContains(t, first, `h="1"`)
+	assert.Contains(t, second, `name="ByProduct"`)
+	assert.Contains(t, second, `<pivotField name="Product" axis="axisRow" compact="true" outline="true" showAll="false" defaultSubtotal="true" sortType="descending">`)
+	assert.Contains(t, second, `<item h="1" x="2"></item>`)
+}
+
+func TestPivotTableBuilder_FieldOptions_RunningTotal(t *testing.T) {
+	wb := newPivotSourceWorkbook()
+	pivotSheet := wb.AddSheet("Pivot Report")
+
+	err := pivotSheet.NewPivotTable("Pivot Report", "Raw Data!A1:C5").
+		AddRowField("Region").
+		AddValueFieldWithOptions("Sales", "sum", excelbuilder.PivotValueFieldOptions{
+			ShowValuesAs: "runningTotal",
+			BaseField:    "Region",
+		}).
+		Build()
+	require.NoError(t, err)
+
+	buf, err := wb.Build().WriteToBuffer()
+	require.NoError(t, err)
+	table := readZipEntry(t, buf.Bytes(), "xl/pivotTables/pivotTable1.xml")
+	assert.Contains(t, table, `<dataField name="sum of Sales" fld="2" subtotal="sum" showDataAs="runTotal" baseField="1">`)
+}
+
+func TestPivotTableBuilder_FieldOptions_Invalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		build func(*excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder
+	}{
+		{"Invalid Sort", func(p *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+			return p.AddRowFieldWithOptions("Region", excelbuilder.PivotFieldOptions{Sort: "random"}).AddValueField("Sales", "sum")
+		}},
+		{"Invalid Show Values As", func(p *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+			return p.AddRowField("Region").AddValueFieldWithOptions("Sales", "sum", excelbuilder.PivotValueFieldOptions{ShowValuesAs: "percentOfEverything"})
+		}},
+		{"Running Total Without Base Field", func(p *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+			return p.AddRowField("Region").AddValueFieldWithOptions("Sales", "sum", excelbuilder.PivotValueFieldOptions{ShowValuesAs: "runningTotal"})
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wb := newPivotSourceWorkbook()
+			pivot := wb.AddSheet("Pivot Report").NewPivotTable("Pivot Report", "Raw Data!A1:C5")
+			assert.Error(t, tc.build(pivot).Build())
+		})
+	}
+}
+
+type pivotSale struct {
+	Product string
+	Region  string
+	Sales   float64
+	note    string
+}
+
+func TestWorkbookBuilder_AddPivotFromRecords_Structs(t *testing.T) {
+	wb := excelbuilder.New().NewWorkbook()
+	wb.AddSheet("Summary")
+	records := []pivotSale{
+		{"Laptop", "North", 1000, ""},
+		{"Monitor", "North", 500, ""},
+		{"Laptop", "South", 1500, ""},
+	}
+
+	err := wb.AddPivotFromRecords(records, excelbuilder.PivotSpec{
+		TargetSheet: "Pivot Report",
+		TargetCell:  "B2",
+		RowFields:   []excelbuilder.PivotField{{Name: "Region"}},
+		ValueFields: []excelbuilder.PivotField{{Name: "Sales", Function: "sum"}},
+	})
+	require.NoError(t, err)
+
+	file := wb.Build()
+	rows, err := file.GetRows("Pivot Report_Data")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Product", "Region", "Sales"}, rows[0], "Unexported fields should be skipped")
+	assert.Len(t, rows, 4)
+
+	visible, err := file.GetSheetVisible("Pivot Report_Data")
+	require.NoError(t, err)
+	assert.False(t, visible, "Source sheet should be hidden")
+	assert.Equal(t, "Summary", file.GetSheetName(file.GetActiveSheetIndex()), "Active sheet should not change")
+
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+	cache := readZipEntry(t, buf.Bytes(), "xl/pivotCache/pivotCacheDefinition1.xml")
+	assert.Contains(t, cache, `<worksheetSource ref="A1:C4" sheet="Pivot Report_Data">`)
+}
+
+func TestWorkbookBuilder_AddPivotFromRecords_StructColumns(t *testing.T) {
+	wb := excelbuilder.New().NewWorkbook()
+	records := []*pivotSale{
+		{"Laptop", "North", 1000, ""},
+		{"Laptop", "South", 1500, ""},
+	}
+
+	err := wb.AddPivotFromRecords(records, excelbuilder.PivotSpec{
+		TargetSheet: "Pivot",
+		Columns:     []string{"Sales", "Region"},
+		RowFields:   []excelbuilder.PivotField{{Name: "Region"}},
+		ValueFields: []excelbuilder.PivotField{{Name: "Sales", Function: "sum"}},
+	})
+	require.NoError(t, err)
+
+	rows, err := wb.Build().GetRows("Pivot_Data")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"Sales", "Region"}, {"1000", "North"}, {"1500", "South"}}, rows)
+}
+
+func TestWorkbookBuilder_AddPivotFromRecords_Maps(t *testing.T) {
+	wb := excelbuilder.New().NewWorkbook()
+	records := []map[string]interface{}{
+		{"Region": "North", "Sales": 1000},
+		{"Region": "South", "Sales": 1500, "Channel": "Online"},
+	}
+
+	err := wb.AddPivotFromRecords(records, excelbuilder.PivotSpec{
+		SourceSheet: "Sales Source",
+		TargetSheet: "Pivot",
+		RowFields:   []excelbuilder.PivotField{{Name: "Region"}},
+		ValueFields: []excelbuilder.PivotField{{Name: "Sales", Function: "sum"}},
+	})
+	require.NoError(t, err)
+
+	rows, err := wb.Build().GetRows("Sales Source")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Channel", "Region", "Sales"}, rows[0], "Header should be the sorted union of keys")
+	assert.Equal(t, []string{"", "North", "1000"}, rows[1])
+}
+
+func TestWorkbookBuilder_AddPivotFromRecords_RetryAfterError(t *testing.T) {
+	wb := excelbuilder.New().NewWorkbook()
+	wb.AddSheet("Summary")
+	records := []map[string]interface{}{
+		{"Region": "North", "Sales": 1000},
+		{"Region": "South", "Sales": 1500},
+	}
+	spec := excelbuilder.PivotSpec{
+		TargetSheet: "P",
+		RowFields:   []excelbuilder.PivotField{{Name: "Regoin"}},
+		ValueFields: []excelbuilder.PivotField{{Name: "Sales", Function: "sum"}},
+	}
+
+	require.Error(t, wb.AddPivotFromRecords(records, spec))
+	file := wb.Build()
+	assert.NotContains(t, file.GetSheetList(), "P_Data", "A failed call should remove its source sheet")
+	assert.Equal(t, "Summary", file.GetSheetName(file.GetActiveSheetIndex()))
+
+	spec.RowFields = []excelbuilder.PivotField{{Name: "Region"}}
+	require.NoError(t, wb.AddPivotFromRecords(records, spec))
+	rows, err := file.GetRows("P_Data")
+	require.NoError(t, err)
+	assert.Len(t, rows, 3)
+}
+
+func TestWorkbookBuilder_AddPivotFromRecords_Errors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		records interface{}
+		spec    excelbuilder.PivotSpec
+	}{
+		{"Not A Slice", pivotSale{}, excelbuilder.PivotSpec{TargetSheet: "Pivot"}},
+		{"No Records", []pivotSale{}, excelbuilder.PivotSpec{TargetSheet: "Pivot"}},
+		{"Unsupported Records", []int{1, 2}, excelbuilder.PivotSpec{TargetSheet: "Pivot"}},
+		{"Missing Target", []pivotSale{{Region: "North"}}, excelbuilder.PivotSpec{}},
+		{"Unknown Struct Column", []pivotSale{{Region: "North"}}, excelbuilder.PivotSpec{TargetSheet: "Pivot", Columns: []string{"Region", "Country"}}},
+		{"Unexported Struct Column", []pivotSale{{Region: "North"}}, excelbuilder.PivotSpec{TargetSheet: "Pivot", Columns: []string{"note"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wb := excelbuilder.New().NewWorkbook()
+			assert.Error(t, wb.AddPivotFromRecords(tc.records, tc.spec))
+		})
+	}
+}
+
+func TestPivotTableBuilder_Validate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		build       func(*excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder
+		expectedErr error
+		field       string
+		role        string
+	}{
+		{"Unknown Row Field", func(p *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+			return p.AddRowField("region").AddValueField("Sales", "sum")
+		}, excelbuilder.ErrPivotFieldNotFound, "region", "row"},
+		{"Unknown Value Field", func(p *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+			return p.AddRowField("Region").AddValueField("Revenue", "sum")
+		}, excelbuilder.ErrPivotFieldNotFound, "Revenue", "value"},
+		{"Unknown Filter Field", func(p *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+			return p.AddRowField("Region").AddFilterField("Year").AddValueField("Sales", "sum")
+		}, excelbuilder.ErrPivotFieldNotFound, "Year", "filter"},
+		{"Field In Two Roles", func(p *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+			return p.AddRowField("Region").AddColumnField("Region").AddValueField("Sales", "sum")
+		}, excelbuilder.ErrPivotFieldDuplicate, "Region", "column"},
+		{"Invalid Function", func(p *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+			return p.AddRowField("Region").AddValueField("Sales", "median")
+		}, excelbuilder.ErrPivotFunctionInvalid, "Sales", "value"},
+		{"Sum Of Text Column", func(p *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+			return p.AddRowField("Region").AddValueField("Product", "sum")
+		}, excelbuilder.ErrPivotFunctionMismatch, "Product", "value"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wb := newPivotSourceWorkbook()
+			pivot := wb.AddSheet("Pivot Report").NewPivotTable("Pivot Report", "Raw Data!A1:C5")
+
+			err := tc.build(pivot).Build()
+
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			var fieldErr *excelbuilder.PivotFieldError
+			require.ErrorAs(t, err, &fieldErr)
+			assert.Equal(t, tc.field, fieldErr.Field)
+			assert.Equal(t, tc.role, fieldErr.Role)
+			assert.Contains(t, err.Error(), tc.field, "Error message should name the bad field")
+		})
+	}
+}
+
+func TestPivotTableBuilder_Validate_Valid(t *testing.T) {
+	wb := newPivotSourceWorkbook()
+	pivot := wb.AddSheet("Pivot Report").NewPivotTable("Pivot Report", "Raw Data!A1:C5").
+		AddRowField("Region").
+		AddValueField("Product", "count").
+		AddValueField("Product", "countNums").
+		AddValueField("Sales", "Average")
+
+	assert.NoError(t, pivot.Validate())
+}
+
+func newPivotOrdersWorkbook() *excelbuilder.WorkbookBuilder {
+	wb := excelbuilder.New().NewWorkbook()
+	orders := wb.AddSheet("Orders")
+	orders.AddRow().AddCells("Date", "Unit Price", "Revenue", "Profit")
+	orders.AddRow().AddCells(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), 5, 200, 50)
+	orders.AddRow().AddCells("2024-05-03", 12, 400, 40)
+	orders.AddRow().AddCells(time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC), 25, 0, 10)
+	return wb
+}
+
+func TestPivotTableBuilder_DerivedFields(t *testing.T) {
+	wb := newPivotOrdersWorkbook()
+	wb.AddSheet("Pivot Report")
+
+	err := wb.AddSheet("Unused").NewPivotTable("Pivot Report", "Orders!A1:D4").
+		AddDateGroup("Year", "Date", "year").
+		AddDateGroup("Quarter", "Date", "quarter").
+		AddDateGroup("Month", "Date", "month").
+		AddNumberGroup("Price Band", "Unit Price", 0, 10).
+		AddCalculatedField("Margin", "Profit / Revenue").
+		AddCalculatedField("Margin %", "Margin * 100").
+		AddRowField("Year").
+		AddColumnField("Price Band").
+		AddValueField("Margin", "average").
+		Build()
+	require.NoError(t, err)
+
+	file := wb.Build()
+	rows, err := file.GetRows("Orders")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Date", "Unit Price", "Revenue", "Profit", "Year", "Quarter", "Month", "Price Band", "Margin", "Margin %"}, rows[0])
+	assert.Equal(t, []string{"2024", "2024-Q1", "2024-01", "[0, 10)", "0.25", "25"}, rows[1][4:])
+	assert.Equal(t, []string{"2024", "2024-Q2", "2024-05", "[10, 20)", "0.1", "10"}, rows[2][4:])
+	assert.Equal(t, []string{"2025", "2025-Q4", "2025-11", "[20, 30)"}, rows[3][4:], "Division by zero should leave the cell blank")
+
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+	cache := readZipEntry(t, buf.Bytes(), "xl/pivotCache/pivotCacheDefinition1.xml")
+	assert.Contains(t, cache, `<worksheetSource ref="A1:J4" sheet="Orders">`)
+}
+
+func TestPivotTableBuilder_NumberGroupOrder(t *testing.T) {
+	wb := excelbuilder.New().NewWorkbook()
+	data := wb.AddSheet("Data")
+	data.AddRow().AddCells("Change", "Count")
+	for _, change := range []int{12, -15, 7, 25, -3} {
+		data.AddRow().AddCells(change, 1)
+	}
+
+	err := data.NewPivotTable("Pivot", "Data!A1:B6").
+		AddNumberGroup("Band", "Change", 0, 10).
+		AddRowField("Band").
+		AddValueField("Count", "sum").
+		Build()
+	require.NoError(t, err)
+
+	file := wb.Build()
+	bands, err := file.GetCols("Data")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Band", "[10, 20)", "[-20, -10)", "[0, 10)", "[20, 30)", "[-10, 0)"}, bands[2])
+
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+	cache := readZipEntry(t, buf.Bytes(), "xl/pivotCache/pivotCacheDefinition1.xml")
+	positions := make([]int, 0, 5)
+	for _, label := range []string{"[-20, -10)", "[-10, 0)", "[0, 10)", "[10, 20)", "[20, 30)"} {
+		position := strings.Index(cache, `v="`+label+`"`)
+		require.NotEqual(t, -1, position, label)
+		positions = append(positions, position)
+	}
+	assert.IsIncreasing(t, positions, "Bins should be listed in numeric order")
+}
+
+func TestPivotTableBuilder_DerivedFields_BracketedNames(t *testing.T) {
+	wb := newPivotOrdersWorkbook()
+	wb.AddSheet("Pivot Report")
+
+	err := wb.AddSheet("Unused").NewPivotTable("Pivot Report", "Orders!A1:D4").
+		AddCalculatedField("Cost", "-([Unit Price] * 2 - Revenue)").
+		AddRowField("Unit Price").
+		AddValueField("Cost", "sum").
+		Build()
+	require.NoError(t, err)
+
+	rows, err := wb.Build().GetRows("Orders")
+	require.NoError(t, err)
+	assert.Equal(t, "190", rows[1][4])
+	assert.Equal(t, "-50", rows[3][4])
+}
+
+func TestPivotTableBuilder_DerivedFields_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder
+		err   string
+	}{
+		{
+			name: "unknown source field",
+			setup: func(ptb *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+				return ptb.AddDateGroup("Year", "Ship Date", "year")
+			},
+			err: "source field 'Ship Date' not found",
+		},
+		{
+			name: "unsupported grouping",
+			setup: func(ptb *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+				return ptb.AddDateGroup("Week", "Date", "week")
+			},
+			err: "unsupported date grouping 'week'",
+		},
+		{
+			name: "invalid interval",
+			setup: func(ptb *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+				return ptb.AddNumberGroup("Band", "Revenue", 0, 0)
+			},
+			err: "interval must be positive",
+		},
+		{
+			name: "unknown expression field",
+			setup: func(ptb *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+				return ptb.AddCalculatedField("Margin", "Profit / Sales")
+			},
+			err: "field 'Sales' not found",
+		},
+		{
+			name: "malformed expression",
+			setup: func(ptb *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+				return ptb.AddCalculatedField("Margin", "(Profit / Revenue")
+			},
+			err: "missing closing parenthesis",
+		},
+		{
+			name: "number without date format",
+			setup: func(ptb *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+				return ptb.AddDateGroup("Year", "Unit Price", "year")
+			},
+			err: "cannot parse '5' as a date, the cell has no date format",
+		},
+		{
+			name: "existing field name",
+			setup: func(ptb *excelbuilder.PivotTableBuilder) *excelbuilder.PivotTableBuilder {
+				return ptb.AddCalculatedField("Profit", "Revenue * 2")
+			},
+			err: "derived field 'Profit' already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wb := newPivotOrdersWorkbook()
+			ptb := wb.AddSheet("Pivot Report").NewPivotTable("Pivot Report", "Orders!A1:D4")
+			err := tt.setup(ptb).AddRowField("Date").AddValueField("Revenue", "sum").Build()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestPivotTableBuilder_DerivedFields_OccupiedColumn(t *testing.T) {
+	wb := newPivotOrdersWorkbook()
+	file := wb.Build()
+	require.NoError(t, file.SetCellValue("Orders", "E3", "note"))
+
+	err := wb.AddSheet("Pivot Report").NewPivotTable("Pivot Report", "Orders!A1:D4").
+		AddDateGroup("Year", "Date", "year").
+		AddRowField("Year").
+		AddValueField("Revenue", "sum").
+		Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Orders!E3, cell is not empty")
+}
+
+func TestPivotTableBuilder_DerivedFields_InvalidPivotWritesNothing(t *testing.T) {
+	wb := newPivotOrdersWorkbook()
+	err := wb.AddSheet("Pivot Report").NewPivotTable("Pivot Report", "Orders!A1:D4").
+		AddDateGroup("Year", "Date", "year").
+		AddRowField("Year").
+		AddValueField("Region", "sum").
+		Build()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, excelbuilder.ErrPivotFieldNotFound)
+
+	rows, err := wb.Build().GetRows("Orders")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Date", "Unit Price", "Revenue", "Profit"}, rows[0], "Derived columns should not be written")
+}
+
+func TestPivotTableBuilder_DerivedFields_Validate(t *testing.T) {
+	wb := newPivotOrdersWorkbook()
+	pivot := wb.AddSheet("Pivot Report").NewPivotTable("Pivot Report", "Orders!A1:D4").
+		AddDateGroup("Year", "Date", "year").
+		AddCalculatedField("Margin", "Profit / Revenue").
+		AddRowField("Year").
+		AddValueField("Margin", "average")
+
+	require.NoError(t, pivot.Validate())
+	rows, err := wb.Build().GetRows("Orders")
+	require.NoError(t, err)
+	assert.Len(t, rows[0], 4, "Validate should not write derived columns")
+	require.NoError(t, pivot.Build())
+}
+
+func TestPivotTableBuilder_AddSlicer(t *testing.T) {
+	wb := newPivotSourceWorkbook()
+	pivotSheet := wb.AddSheet("Pivot Report")
+
+	err := pivotSheet.NewPivotTable("Pivot Report", "Raw Data!A1:C5").
+		SetName("SalesPivot").
+		AddRowFieldWithOptions("Region", excelbuilder.PivotFieldOptions{Sort: "descending"}).
+		AddValueField("Sales", "sum").
+		AddSlicer("Region", "F2", excelbuilder.SlicerOptions{}).
+		AddSlicer("Product", "F16", excelbuilder.SlicerOptions{Caption: "Product line", HideHeader: true}).
+		Build()
+	require.NoError(t, err)
+
+	file := wb.Build()
+	pivots, err := file.GetPivotTables("Pivot Report")
+	require.NoError(t, err, "Pivot tables with slicers should be readable")
+	require.Len(t, pivots, 1)
+	assert.Equal(t, "SalesPivot", pivots[0].Name)
+
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+	table := readZipEntry(t, buf.Bytes(), "xl/pivotTables/pivotTable1.xml")
+	assert.Contains(t, table, `sortType="descending"`, "Field options should be kept")
+	slicer := readZipEntry(t, buf.Bytes(), "xl/slicers/slicer1.xml")
+	assert.Contains(t, slicer, `name="Region" cache="Slicer_Region" caption="Region"`)
+	assert.Contains(t, slicer, `name="Product" cache="Slicer_Product" caption="Product line" showCaption="false"`)
+	cache := readZipEntry(t, buf.Bytes(), "xl/slicerCaches/slicerCache1.xml")
+	assert.Contains(t, cache, `<pivotTable tabId=`)
+}
+
+func TestPivotTableBuilder_AddSlicer_TwoPivotsSameField(t *testing.T) {
+	wb := newPivotSourceWorkbook()
+	pivotSheet := wb.AddSheet("Pivot Report")
+
+	err := pivotSheet.NewPivotTable("Pivot Report", "Raw Data!A1:C5").
+		SetName("ByRegion").
+		AddRowFieldWithOptions("Region", excelbuilder.PivotFieldOptions{Sort: "descending"}).
+		AddValueField("Sales", "sum").
+		AddSlicer("Region", "F2", excelbuilder.SlicerOptions{}).
+		Build()
+	require.NoError(t, err)
+	err = pivotSheet.NewPivotTable("Pivot Report", "Raw Data!A1:C5").
+		SetName("ByProduct").
+		SetTargetCell("J1").
+		AddRowField("Product").
+		AddValueField("Sales", "sum").
+		AddSlicer("Region", "F16", excelbuilder.SlicerOptions{Caption: "Product region"}).
+		Build()
+	require.NoError(t, err)
+
+	file := wb.Build()
+	pivots, err := file.GetPivotTables("Pivot Report")
+	require.NoError(t, err)
+	require.Len(t, pivots, 2)
+
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+	first := readZipEntry(t, buf.Bytes(), "xl/slicerCaches/slicerCache1.xml")
+	second := readZipEntry(t, buf.Bytes(), "xl/slicerCaches/slicerCache2.xml")
+	assert.Contains(t, first, `sourceName="Region"`)
+	assert.Contains(t, first, `name="ByRegion"`)
+	assert.NotContains(t, first, `name="ByProduct"`)
+	assert.Contains(t, second, `sourceName="Region"`)
+	assert.Contains(t, second, `name="ByProduct"`)
+	assert.NotContains(t, second, `name="ByRegion"`)
+
+	slicer := readZipEntry(t, buf.Bytes(), "xl/slicers/slicer1.xml")
+	assert.Contains(t, slicer, `cache="Slicer_Region" caption="Region"`)
+	assert.Contains(t, slicer, `cache="Slicer_Region1" caption="Product region"`)
+	table := readZipEntry(t, buf.Bytes(), "xl/pivotTables/pivotTable1.xml")
+	assert.Contains(t, table, `sortType="descending"`, "Field options should survive the second pivot's slicer")
+}
+
+func TestPivotTableBuilder_AddSlicer_UnknownField(t *testing.T) {
+	wb := newPivotSourceWorkbook()
+	err := wb.AddSheet("Pivot Report").NewPivotTable("Pivot Report", "Raw Data!A1:C5").
+		AddRowField("Region").
+		AddValueField("Sales", "sum").
+		AddSlicer("Country", "F2", excelbuilder.SlicerOptions{}).
+		Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to add slicer for field 'Country'")
+}
@@ -0,0 +1,85 @@
+package excelbuilder_test
+
+import (
+	"testing"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSheetBuilder_AddSparklines(t *testing.T) {
+	builder := excelbuilder.New().WithErrorCollection(true)
+	wb := builder.NewWorkbook()
+	sheet := wb.AddSheet("KPI")
+	sheet.AddRow().AddCells("KPI", "Q1", "Q2", "Q3", "Q4", "Trend")
+	sheet.AddRow().AddCells("Revenue", 10, 12, 9, 15)
+	sheet.AddRow().AddCells("Margin", 0.2, -0.1, 0.3, 0.25)
+
+	sheet.AddSparklines("F2:F3", "B2:E3", excelbuilder.SparklineOptions{
+		Type:        "column",
+		High:        true,
+		Low:         true,
+		Negative:    true,
+		SeriesColor: "#4472C4",
+	})
+	assert.False(t, builder.HasErrors(), "Sparklines should be added without errors")
+
+	buf, err := wb.Build().WriteToBuffer()
+	require.NoError(t, err)
+	worksheet := readZipEntry(t, buf.Bytes(), "xl/worksheets/sheet2.xml")
+	assert.Contains(t, worksheet, "<xm:f>&#39;KPI&#39;!B2:E2</xm:f><xm:sqref>F2</xm:sqref>")
+	assert.Contains(t, worksheet, "<xm:f>&#39;KPI&#39;!B3:E3</xm:f><xm:sqref>F3</xm:sqref>")
+	assert.Contains(t, worksheet, `type="column"`)
+}
+
+func TestSheetBuilder_AddSparklines_Validation(t *testing.T) {
+	testCases := []struct {
+		name          string
+		locationRange string
+		dataRange     string
+	}{
+		{"Two Dimensional Location", "F2:G3", "B2:E3"},
+		{"Row Count Mismatch", "F2:F4", "B2:E3"},
+		{"Invalid Data Range", "F2:F3", "B2:?"},
+		{"Empty Location", "", "B2:E3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := excelbuilder.New().WithErrorCollection(true)
+			sheet := builder.NewWorkbook().AddSheet("KPI")
+
+			result := sheet.AddSparklines(tc.locationRange, tc.dataRange, excelbuilder.SparklineOptions{})
+
+			assert.Same(t, sheet, result, "Should return the same builder instance")
+			assert.True(t, builder.HasErrors(), "Invalid ranges should be reported")
+		})
+	}
+}
+
+func TestRowBuilder_AddSparkline(t *testing.T) {
+	builder := excelbuilder.New().WithErrorCollection(true)
+	wb := builder.NewWorkbook()
+	sheet := wb.AddSheet("KPI")
+	sheet.AddRow().
+		AddCells("Revenue", 10, 12, 9, 15).
+		AddSparkline(excelbuilder.SparklineOptions{Type: "line", Markers: true})
+
+	assert.False(t, builder.HasErrors())
+
+	buf, err := wb.Build().WriteToBuffer()
+	require.NoError(t, err)
+	worksheet := readZipEntry(t, buf.Bytes(), "xl/worksheets/sheet2.xml")
+	assert.Contains(t, worksheet, "<xm:f>&#39;KPI&#39;!B1:E1</xm:f><xm:sqref>F1</xm:sqref>")
+}
+
+func TestRowBuilder_AddSparkline_NoNumericCells(t *testing.T) {
+	builder := excelbuilder.New().WithErrorCollection(true)
+	row := builder.NewWorkbook().AddSheet("KPI").AddRow().AddCells("Revenue", "n/a")
+
+	result := row.AddSparkline(excelbuilder.SparklineOptions{})
+
+	assert.Same(t, row, result, "Should return the same builder instance")
+	assert.True(t, builder.HasErrors(), "A row without numbers cannot have a sparkline")
+}
@@ -0,0 +1,253 @@
+package excelbuilder_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// stubColumn describes a column of a stub result set
+type stubColumn struct {
+	name     string
+	typeName string
+	scale    int64
+}
+
+// stubResult is the result set returned for a query by the stub driver
+type stubResult struct {
+	columns []stubColumn
+	rows    [][]driver.Value
+	err     error // Returned after the rows
+}
+
+var stubResults = map[string]stubResult{
+	"orders": {
+		columns: []stubColumn{
+			{name: "id", typeName: "BIGINT"},
+			{name: "customer", typeName: "VARCHAR"},
+			{name: "total", typeName: "DECIMAL", scale: 2},
+			{name: "paid", typeName: "BOOL"},
+			{name: "ordered_at", typeName: "TIMESTAMP"},
+			{name: "ship_date", typeName: "DATE"},
+			{name: "rate", typeName: "DOUBLE PRECISION"},
+		},
+		rows: [][]driver.Value{
+			{int64(1), "Ann", []byte("1234.50"), true, time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC), "2024-03-05", 0.25},
+			{int64(2), []byte("Bob"), "7", int64(0), "2024-03-02 10:00:00", nil, nil},
+			{[]byte("3"), nil, nil, nil, nil, time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC), 1.5},
+		},
+	},
+	"bits": {
+		columns: []stubColumn{
+			{name: "active", typeName: "BIT"},
+			{name: "flags", typeName: "BIT"},
+			{name: "amount", typeName: "DECIMAL", scale: 2},
+		},
+		rows: [][]driver.Value{
+			{[]byte{1}, []byte{0x01, 0x02}, []byte("12345678901234567.89")},
+			{[]byte{0}, []byte("101"), []byte("0.10")},
+			{[]byte("1"), int64(6), "-3.5"},
+		},
+	},
+	"broken": {
+		columns: []stubColumn{{name: "n", typeName: "INT"}},
+		rows:    [][]driver.Value{{int64(1)}},
+		err:     errors.New("connection reset"),
+	},
+}
+
+func init() {
+	sql.Register("excelbuilder-stub", stubDriver{})
+}
+
+type stubDriver struct{}
+
+func (stubDriver) Open(name string) (driver.Conn, error) { return stubConn{}, nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(query string) (driver.Stmt, error) { return stubStmt{query: query}, nil }
+func (stubConn) Close() error                              { return nil }
+func (stubConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type stubStmt struct {
+	query string
+}
+
+func (s stubStmt) Close() error  { return nil }
+func (s stubStmt) NumInput() int { return 0 }
+func (s stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	result, ok := stubResults[s.query]
+	if !ok {
+		return nil, errors.New("unknown query")
+	}
+	return &stubRows{result: result}, nil
+}
+
+type stubRows struct {
+	result stubResult
+	next   int
+}
+
+func (r *stubRows) Columns() []string {
+	names := make([]string, len(r.result.columns))
+	for i, column := range r.result.columns {
+		names[i] = column.name
+	}
+	return names
+}
+
+func (r *stubRows) Close() error { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.next == len(r.result.rows) {
+		if r.result.err != nil {
+			return r.result.err
+		}
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+func (r *stubRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.result.columns[index].typeName
+}
+
+func (r *stubRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	column := r.result.columns[index]
+	return 10, column.scale, column.typeName == "DECIMAL"
+}
+
+// queryStub runs a query against the stub driver
+func queryStub(t *testing.T, query string) *sql.Rows {
+	t.Helper()
+	db, err := sql.Open("excelbuilder-stub", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	rows, err := db.Query(query)
+	require.NoError(t, err)
+	t.Cleanup(func() { rows.Close() })
+	return rows
+}
+
+func TestSheetBuilder_AddRowsFromSQL(t *testing.T) {
+	workbook := excelbuilder.New().NewWorkbook()
+	workbook.AddSheet("Orders").
+		AddRowsFromSQL(queryStub(t, "orders"), excelbuilder.SQLImportOptions{
+			ColumnHeaders: map[string]string{"ordered_at": "Ordered"},
+		}).
+		AddRow().AddCells("end")
+	file := workbook.Build()
+
+	rows, err := file.GetRows("Orders")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "customer", "total", "paid", "Ordered", "ship_date", "rate"},
+		{"1", "Ann", "1,234.50", "TRUE", "2024-03-01 09:30:00", "2024-03-05", "0.25"},
+		{"2", "Bob", "7.00", "FALSE", "2024-03-02 10:00:00"},
+		{"3", "", "", "", "", "2024-03-09", "1.5"},
+		{"end"},
+	}, rows)
+
+	tests := []struct {
+		cell     string
+		cellType excelize.CellType
+	}{
+		{cell: "A4", cellType: excelize.CellTypeUnset},
+		{cell: "B3", cellType: excelize.CellTypeSharedString},
+		{cell: "C2", cellType: excelize.CellTypeUnset},
+		{cell: "D3", cellType: excelize.CellTypeBool},
+		{cell: "E3", cellType: excelize.CellTypeUnset},
+		{cell: "B4", cellType: excelize.CellTypeUnset},
+	}
+	for _, tt := range tests {
+		cellType, err := file.GetCellType("Orders", tt.cell)
+		require.NoError(t, err)
+		assert.Equal(t, tt.cellType, cellType, tt.cell)
+	}
+
+	raw, err := file.GetCellValue("Orders", "C2", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.Equal(t, "1234.5", raw)
+
+	styleID, err := file.GetCellStyle("Orders", "A1")
+	require.NoError(t, err)
+	style, err := file.GetStyle(styleID)
+	require.NoError(t, err)
+	assert.True(t, style.Font.Bold)
+}
+
+func TestSheetBuilder_AddRowsFromSQLOptions(t *testing.T) {
+	workbook := excelbuilder.New().NewWorkbook()
+	workbook.AddSheet("Orders").AddRowsFromSQL(queryStub(t, "orders"), excelbuilder.SQLImportOptions{
+		SkipHeader:     true,
+		DecimalFormat:  "0.0",
+		DateFormat:     "dd/mm/yyyy",
+		DateTimeFormat: "yyyy-mm-dd",
+		NullValue:      "NULL",
+	})
+
+	rows, err := workbook.Build().GetRows("Orders")
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"1", "Ann", "1234.5", "TRUE", "2024-03-01", "05/03/2024", "0.25"}, rows[0])
+	assert.Equal(t, []string{"3", "NULL", "NULL", "NULL", "NULL", "09/03/2024", "1.5"}, rows[2])
+}
+
+func TestSheetBuilder_AddRowsFromSQLBitsAndDecimals(t *testing.T) {
+	workbook := excelbuilder.New().NewWorkbook()
+	workbook.AddSheet("Bits").AddRowsFromSQL(queryStub(t, "bits"), excelbuilder.SQLImportOptions{SkipHeader: true})
+	file := workbook.Build()
+
+	tests := []struct {
+		cell     string
+		cellType excelize.CellType
+		raw      string
+	}{
+		{cell: "A1", cellType: excelize.CellTypeBool, raw: "1"},
+		{cell: "A2", cellType: excelize.CellTypeBool, raw: "0"},
+		{cell: "A3", cellType: excelize.CellTypeBool, raw: "1"},
+		{cell: "B1", cellType: excelize.CellTypeUnset, raw: "258"},
+		{cell: "B2", cellType: excelize.CellTypeUnset, raw: "5"},
+		{cell: "B3", cellType: excelize.CellTypeUnset, raw: "6"},
+		// A float64 would round the first decimal to 12345678901234568
+		{cell: "C1", cellType: excelize.CellTypeSharedString, raw: "12345678901234567.89"},
+		{cell: "C2", cellType: excelize.CellTypeUnset, raw: "0.1"},
+		{cell: "C3", cellType: excelize.CellTypeUnset, raw: "-3.5"},
+	}
+	for _, tt := range tests {
+		cellType, err := file.GetCellType("Bits", tt.cell)
+		require.NoError(t, err)
+		assert.Equal(t, tt.cellType, cellType, tt.cell)
+		raw, err := file.GetCellValue("Bits", tt.cell, excelize.Options{RawCellValue: true})
+		require.NoError(t, err)
+		assert.Equal(t, tt.raw, raw, tt.cell)
+	}
+}
+
+func TestSheetBuilder_AddRowsFromSQLErrors(t *testing.T) {
+	builder := excelbuilder.New().WithErrorCollection(true)
+	workbook := builder.NewWorkbook()
+	sheet := workbook.AddSheet("Data").AddRowsFromSQL(queryStub(t, "broken"), excelbuilder.SQLImportOptions{})
+
+	errs := builder.GetCollectedErrors()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "connection reset")
+	assert.Equal(t, 2, sheet.GetCurrentRow())
+
+	workbook.AddSheet("Nil").AddRowsFromSQL(nil, excelbuilder.SQLImportOptions{})
+	assert.Len(t, builder.GetCollectedErrors(), 2)
+}
@@ -0,0 +1,91 @@
+package excelbuilder_test
+
+import (
+	"testing"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSalesTableSheet() (*excelbuilder.WorkbookBuilder, *excelbuilder.SheetBuilder) {
+	wb := excelbuilder.New().NewWorkbook()
+	sheet := wb.AddSheet("Sales")
+	sheet.AddRow().AddCells("Product", "Region", "Sales")
+	sheet.AddRow().AddCells("Laptop", "North", 1000)
+	sheet.AddRow().AddCells("Monitor", "North", 500)
+	sheet.AddRow().AddCells("Laptop", "South", 1500)
+	return wb, sheet
+}
+
+func TestTableBuilder_Build(t *testing.T) {
+	wb, sheet := newSalesTableSheet()
+
+	err := sheet.AddTable("A1:C4").
+		SetName("SalesTable").
+		WithStyle("TableStyleLight9").
+		ShowColumnStripes(true).
+		Build()
+	require.NoError(t, err)
+
+	tables, err := wb.Build().GetTables("Sales")
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	assert.Equal(t, "SalesTable", tables[0].Name)
+	assert.Equal(t, "A1:C4", tables[0].Range)
+	assert.Equal(t, "TableStyleLight9", tables[0].StyleName)
+	assert.True(t, tables[0].ShowColumnStripes)
+}
+
+func TestTableBuilder_DefaultName(t *testing.T) {
+	wb, sheet := newSalesTableSheet()
+
+	require.NoError(t, sheet.AddTable("A1:B4").Build())
+	require.NoError(t, sheet.AddTable("C1:C4").Build())
+
+	tables, err := wb.Build().GetTables("Sales")
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+	assert.Equal(t, "Table1", tables[0].Name)
+	assert.Equal(t, "Table2", tables[1].Name)
+}
+
+func TestTableBuilder_AddSlicer(t *testing.T) {
+	wb, sheet := newSalesTableSheet()
+	wb.AddSheet("Dashboard")
+
+	err := sheet.AddTable("A1:C4").
+		SetName("SalesTable").
+		AddSlicer("Region", "E1", excelbuilder.SlicerOptions{Width: 180, Height: 200}).
+		AddSlicer("Product", "B2", excelbuilder.SlicerOptions{
+			Caption:    "Choose a product",
+			Sheet:      "Dashboard",
+			Descending: true,
+		}).
+		Build()
+	require.NoError(t, err)
+
+	buf, err := wb.Build().WriteToBuffer()
+	require.NoError(t, err)
+	slicers := readZipEntry(t, buf.Bytes(), "xl/slicers/slicer1.xml")
+	assert.Contains(t, slicers, `name="Region" cache="Slicer_Region" caption="Region"`)
+	dashboard := readZipEntry(t, buf.Bytes(), "xl/slicers/slicer2.xml")
+	assert.Contains(t, dashboard, `caption="Choose a product"`)
+	cache := readZipEntry(t, buf.Bytes(), "xl/slicerCaches/slicerCache2.xml")
+	assert.Contains(t, cache, `sortOrder="descending"`)
+}
+
+func TestTableBuilder_Errors(t *testing.T) {
+	t.Run("invalid name", func(t *testing.T) {
+		_, sheet := newSalesTableSheet()
+		err := sheet.AddTable("A1:C4").SetName("Sales Table").Build()
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown slicer field", func(t *testing.T) {
+		_, sheet := newSalesTableSheet()
+		err := sheet.AddTable("A1:C4").AddSlicer("Country", "E1", excelbuilder.SlicerOptions{}).Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to add slicer for field 'Country'")
+	})
+}
@@ -0,0 +1,177 @@
+package excelbuilder_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// readArchive returns the workbooks of a zip archive keyed by entry name, in archive order
+func readArchive(t *testing.T, data []byte) ([]string, map[string]*excelize.File) {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var names []string
+	files := make(map[string]*excelize.File)
+	for _, entry := range reader.File {
+		rc, err := entry.Open()
+		require.NoError(t, err)
+		file, err := excelize.OpenReader(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		names = append(names, entry.Name)
+		files[entry.Name] = file
+	}
+	return names, files
+}
+
+func statementRecords(count int) []map[string]interface{} {
+	records := make([]map[string]interface{}, count)
+	for i := range records {
+		records[i] = map[string]interface{}{
+			"customer": fmt.Sprintf("Customer %02d", i),
+			"balance":  float64(i) * 10,
+		}
+	}
+	return records
+}
+
+func newStatementTemplate() *excelbuilder.TemplateBuilder {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Statement").
+		AddRow().AddCell().WithValue("{{customer | upper}}").Done().Done().
+		AddRow().AddCell().WithValue("{{balance}}").Done().Done()
+	return tb
+}
+
+func TestTemplateBuilder_RenderMany(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			tb := newStatementTemplate().WithConcurrency(workers)
+			records := statementRecords(12)
+
+			var buf bytes.Buffer
+			err := tb.RenderMany(records, func(index int, record map[string]interface{}) string {
+				return fmt.Sprintf("statements/%s", record["customer"])
+			}, &buf)
+			require.NoError(t, err)
+
+			names, files := readArchive(t, buf.Bytes())
+			require.Len(t, names, 12)
+			for i, name := range names {
+				assert.Equal(t, fmt.Sprintf("statements/Customer %02d.xlsx", i), name)
+				customer, err := files[name].GetCellValue("Statement", "A1")
+				require.NoError(t, err)
+				assert.Equal(t, fmt.Sprintf("CUSTOMER %02d", i), customer)
+				balance, err := files[name].GetCellValue("Statement", "A2")
+				require.NoError(t, err)
+				assert.Equal(t, fmt.Sprint(i*10), balance)
+			}
+
+			// The template itself is not processed
+			value, err := tb.GetCellValue("Statement", "A1")
+			require.NoError(t, err)
+			assert.Equal(t, "{{customer | upper}}", value)
+		})
+	}
+}
+
+func TestTemplateBuilder_RenderManyRecordErrors(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Statement").
+		AddRow().AddCell().WithValue("{{customer}}").Done().Done().
+		AddRow().AddCell().WithValue("{{balance | money}}").Done().Done()
+	tb.RegisterFilter("money", func(value interface{}, args ...string) (interface{}, error) {
+		if _, ok := value.(float64); !ok {
+			return nil, fmt.Errorf("balance must be a number")
+		}
+		return fmt.Sprintf("%.2f", value), nil
+	})
+
+	records := statementRecords(4)
+	records[1]["balance"] = "n/a"
+	records[3]["customer"] = "Customer 00"
+	records = append(records, map[string]interface{}{"balance": 1.0})
+
+	var buf bytes.Buffer
+	err := tb.RenderMany(records, func(index int, record map[string]interface{}) string {
+		customer, _ := record["customer"].(string)
+		return customer
+	}, &buf)
+
+	var renderErr *excelbuilder.RenderManyError
+	require.True(t, errors.As(err, &renderErr), "unexpected error %v", err)
+	require.Len(t, renderErr.Records, 3)
+	assert.Equal(t, 1, renderErr.Records[0].Index)
+	assert.Equal(t, "Customer 01.xlsx", renderErr.Records[0].Name)
+	assert.Contains(t, renderErr.Records[0].Error(), "balance must be a number")
+	assert.Equal(t, 3, renderErr.Records[1].Index)
+	assert.Contains(t, renderErr.Records[1].Error(), "already used")
+	assert.Equal(t, 4, renderErr.Records[2].Index)
+	assert.Contains(t, renderErr.Records[2].Error(), "name is empty")
+
+	names, files := readArchive(t, buf.Bytes())
+	assert.Equal(t, []string{"Customer 00.xlsx", "Customer 02.xlsx"}, names)
+	balance, err := files["Customer 02.xlsx"].GetCellValue("Statement", "A2")
+	require.NoError(t, err)
+	assert.Equal(t, "20.00", balance)
+}
+
+// failingWriter accepts limit bytes and then fails
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return 0, io.ErrShortWrite
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestTemplateBuilder_RenderManyWriteError(t *testing.T) {
+	tb := newStatementTemplate().WithConcurrency(2)
+	err := tb.RenderMany(statementRecords(20), func(index int, record map[string]interface{}) string {
+		return fmt.Sprint(index)
+	}, &failingWriter{limit: 1024})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, io.ErrShortWrite)
+	var renderErr *excelbuilder.RenderManyError
+	assert.False(t, errors.As(err, &renderErr))
+}
+
+func TestTemplateBuilder_RenderManyUnsafeNames(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Statement").AddRow().AddCell().WithValue("{{customer}}")
+
+	names := []string{"../evil", "/etc/evil", "a/../../evil", "reports/./a/../ok", "."}
+	records := statementRecords(len(names))
+	var buf bytes.Buffer
+	err := tb.RenderMany(records, func(index int, record map[string]interface{}) string {
+		return names[index]
+	}, &buf)
+
+	var renderErr *excelbuilder.RenderManyError
+	require.True(t, errors.As(err, &renderErr), "unexpected error %v", err)
+	require.Len(t, renderErr.Records, 4)
+	for i, index := range []int{0, 1, 2} {
+		assert.Equal(t, index, renderErr.Records[i].Index)
+		assert.Contains(t, renderErr.Records[i].Error(), "outside the archive")
+	}
+	assert.Equal(t, 4, renderErr.Records[3].Index)
+	assert.Contains(t, renderErr.Records[3].Error(), "name is empty")
+
+	archived, _ := readArchive(t, buf.Bytes())
+	assert.Equal(t, []string{"reports/ok.xlsx"}, archived)
+}
@@ -0,0 +1,742 @@
+package excelbuilder_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// newInvoiceTemplate returns a template with a line item row between a
+// header and a total row
+func newInvoiceTemplate(t *testing.T) *excelbuilder.TemplateBuilder {
+	t.Helper()
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Invoice").
+		AddRow().AddCell().WithValue("Invoice {{number}}").Done().Done().
+		AddRow().
+		AddCell().WithValue("SKU").Done().
+		AddCell().WithValue("Qty").Done().
+		AddCell().WithValue("Price").Done().
+		Done().
+		AddRow().
+		AddCell().WithValue("{{#each items}}{{sku}}").Done().
+		AddCell().WithValue("{{qty}}").Done().
+		AddCell().WithValue("{{price}}{{/each}}").Done().
+		Done()
+
+	file := tb.Build()
+	require.NoError(t, file.SetCellValue("Invoice", "A4", "Total"))
+	require.NoError(t, file.SetCellFormula("Invoice", "C4", "SUM(C3:C3)"))
+	require.NoError(t, file.SetCellFormula("Invoice", "D4", "SUM(C2:C3)"))
+	require.NoError(t, file.SetRowHeight("Invoice", 3, 24))
+	require.NoError(t, file.MergeCell("Invoice", "D3", "E3"))
+
+	style, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	require.NoError(t, err)
+	require.NoError(t, file.SetCellStyle("Invoice", "A3", "A3", style))
+	return tb
+}
+
+func TestTemplateBuilder_EachLoop(t *testing.T) {
+	tb := newInvoiceTemplate(t)
+	tb.ProcessTemplate(map[string]interface{}{
+		"number": "INV-7",
+		"items": []map[string]interface{}{
+			{"sku": "A-1", "qty": 2, "price": 10},
+			{"sku": "B-2", "qty": 1, "price": 25},
+			{"sku": "C-3", "qty": 5, "price": 4},
+		},
+	})
+	file := tb.Build()
+
+	rows, err := file.GetRows("Invoice")
+	require.NoError(t, err)
+	require.Len(t, rows, 6)
+	assert.Equal(t, []string{"Invoice INV-7"}, rows[0])
+	assert.Equal(t, []string{"A-1", "2", "10"}, rows[2])
+	assert.Equal(t, []string{"B-2", "1", "25"}, rows[3])
+	assert.Equal(t, []string{"C-3", "5", "4"}, rows[4])
+	assert.Equal(t, "Total", rows[5][0])
+
+	formula, err := file.GetCellFormula("Invoice", "C6")
+	require.NoError(t, err)
+	assert.Equal(t, "SUM(C3:C5)", formula, "Range over the template row should cover every item")
+	formula, err = file.GetCellFormula("Invoice", "D6")
+	require.NoError(t, err)
+	assert.Equal(t, "SUM(C2:C5)", formula)
+
+	sourceStyle, err := file.GetCellStyle("Invoice", "A3")
+	require.NoError(t, err)
+	for _, row := range []int{4, 5} {
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		style, err := file.GetCellStyle("Invoice", cell)
+		require.NoError(t, err)
+		assert.Equal(t, sourceStyle, style, "Style should be copied to %s", cell)
+
+		height, err := file.GetRowHeight("Invoice", row)
+		require.NoError(t, err)
+		assert.Equal(t, 24.0, height, "Row height should be copied to row %d", row)
+	}
+
+	merges, err := file.GetMergeCells("Invoice")
+	require.NoError(t, err)
+	var refs []string
+	for _, merge := range merges {
+		refs = append(refs, merge.GetStartAxis()+":"+merge.GetEndAxis())
+	}
+	assert.ElementsMatch(t, []string{"D3:E3", "D4:E4", "D5:E5"}, refs)
+}
+
+func TestTemplateBuilder_EachLoop_MultiRowBlock(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Statement").
+		AddRow().AddCell().WithValue("{{#each accounts}}Account {{@index}}: {{Name}}").Done().Done().
+		AddRow().AddCell().WithValue("Balance {{Balance}} {{currency}}{{/each}}").Done().Done().
+		AddRow().AddCell().WithValue("End").Done().Done()
+
+	type account struct {
+		Name    string
+		Balance float64
+	}
+	tb.ProcessTemplate(map[string]interface{}{
+		"currency": "EUR",
+		"accounts": []account{{"Savings", 120.5}, {"Checking", 80}},
+	})
+
+	rows, err := tb.Build().GetRows("Statement")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Account 0: Savings"},
+		{"Balance 120.5 EUR"},
+		{"Account 1: Checking"},
+		{"Balance 80 EUR"},
+		{"End"},
+	}, rows)
+}
+
+func TestTemplateBuilder_EachLoop_Empty(t *testing.T) {
+	tb := newInvoiceTemplate(t)
+	tb.ProcessTemplate(map[string]interface{}{"number": "INV-8", "items": []string{}})
+
+	rows, err := tb.Build().GetRows("Invoice")
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "Total", rows[2][0], "Template row should be removed")
+}
+
+func TestTemplateBuilder_EachLoop_Scalars(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Tags").
+		AddRow().AddCell().WithValue("{{#each tags}}#{{this}}{{/each}}").Done().Done()
+	tb.ProcessTemplate(map[string]interface{}{"tags": []string{"go", "excel"}})
+
+	rows, err := tb.Build().GetRows("Tags")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"#go"}, {"#excel"}}, rows)
+}
+
+func TestTemplateBuilder_EachLoop_MissingKey(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Sheet").
+		AddRow().AddCell().WithValue("{{#each items}}{{sku}}{{/each}}").Done().Done().
+		AddRow().AddCell().WithValue("{{#each tags}}{{this}}{{/each}}").Done().Done()
+	tb.ProcessTemplate(map[string]interface{}{"tags": []string{"a", "b"}})
+
+	rows, err := tb.Build().GetRows("Sheet")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"{{#each items}}{{sku}}{{/each}}"}, {"a"}, {"b"}}, rows, "Blocks without data should be left untouched")
+}
+
+func TestTemplateBuilder_EachLoop_Unclosed(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Sheet").
+		AddRow().AddCell().WithValue("{{#each items}}{{sku}}").Done().Done()
+	tb.ProcessTemplate(map[string]interface{}{"items": []int{1}})
+
+	value, err := tb.GetCellValue("Sheet", "A1")
+	require.NoError(t, err)
+	assert.Equal(t, "{{#each items}}{{sku}}", value, "Unclosed blocks should be left untouched")
+
+	errs := tb.Errors()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "has no matching {{/each}}")
+}
+
+func TestTemplateBuilder_EachLoop_NotASlice(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Sheet").
+		AddRow().AddCell().WithValue("{{#each items}}{{sku}}{{/each}}").Done().Done()
+	tb.ProcessTemplate(map[string]interface{}{"items": 3})
+
+	value, err := tb.GetCellValue("Sheet", "A1")
+	require.NoError(t, err)
+	assert.Equal(t, "{{#each items}}{{sku}}{{/each}}", value)
+	errs := tb.Errors()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "requires a slice")
+}
+
+func TestTemplateBuilder_NativeTypes(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Report").
+		AddRow().
+		AddCell().WithValue("{{amount}}").Done().
+		AddCell().WithValue("{{ paid }}").Done().
+		AddCell().WithValue("{{due}}").Done().
+		AddCell().WithValue("Total: {{amount}}").Done().
+		AddCell().WithValue("{{count}}").Done().
+		AddCell().WithValue("{{missing}}").Done().
+		Done()
+
+	file := tb.Build()
+	currency := "#,##0.00"
+	style, err := file.NewStyle(&excelize.Style{CustomNumFmt: &currency})
+	require.NoError(t, err)
+	require.NoError(t, file.SetCellStyle("Report", "A1", "A1", style))
+	dateFormat := "yyyy-mm-dd"
+	dateStyle, err := file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	require.NoError(t, err)
+	require.NoError(t, file.SetCellStyle("Report", "C1", "C1", dateStyle))
+
+	tb.ProcessTemplate(map[string]interface{}{
+		"amount": 1234.5,
+		"paid":   true,
+		"due":    time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		"count":  42,
+	})
+
+	assertCell := func(cell string, cellType excelize.CellType, raw, formatted string) {
+		t.Helper()
+		actualType, err := file.GetCellType("Report", cell)
+		require.NoError(t, err)
+		assert.Equal(t, cellType, actualType, cell)
+		value, err := file.GetCellValue("Report", cell, excelize.Options{RawCellValue: true})
+		require.NoError(t, err)
+		assert.Equal(t, raw, value, cell)
+		value, err = file.GetCellValue("Report", cell)
+		require.NoError(t, err)
+		assert.Equal(t, formatted, value, cell)
+	}
+	assertCell("A1", excelize.CellTypeUnset, "1234.5", "1,234.50")
+	assertCell("B1", excelize.CellTypeBool, "1", "TRUE")
+	assertCell("C1", excelize.CellTypeUnset, "45382", "2024-03-31")
+	assertCell("D1", excelize.CellTypeSharedString, "Total: 1234.5", "Total: 1234.5")
+	assertCell("E1", excelize.CellTypeUnset, "42", "42")
+	assertCell("F1", excelize.CellTypeSharedString, "{{missing}}", "{{missing}}")
+
+	styleID, err := file.GetCellStyle("Report", "A1")
+	require.NoError(t, err)
+	assert.Equal(t, style, styleID, "Cell style should be kept")
+}
+
+type templateAddress struct {
+	City    string
+	ZipCode string `json:"zip"`
+}
+
+type templateCustomer struct {
+	Name    string
+	Address *templateAddress
+}
+
+func TestTemplateBuilder_NestedPaths(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Order").
+		AddRow().
+		AddCell().WithValue("{{customer.Address.City}}").Done().
+		AddCell().WithValue("{{customer.address.zip}}").Done().
+		AddCell().WithValue("{{items[1].sku}} x {{items[1].qty}}").Done().
+		AddCell().WithValue("{{meta.tags[0]}}").Done().
+		AddCell().WithValue("{{items[5].sku}}").Done().
+		AddCell().WithValue("{{customer.Phone}}").Done().
+		Done()
+
+	tb.ProcessTemplate(map[string]interface{}{
+		"customer": templateCustomer{Name: "Ada", Address: &templateAddress{City: "London", ZipCode: "N1"}},
+		"items": []map[string]interface{}{
+			{"sku": "A-1", "qty": 2},
+			{"sku": "B-2", "qty": 7},
+		},
+		"meta": map[string]interface{}{"tags": []string{"priority"}},
+	})
+
+	rows, err := tb.Build().GetRows("Order")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"London", "N1", "B-2 x 7", "priority", "{{items[5].sku}}", "{{customer.Phone}}"}, rows[0])
+}
+
+func TestTemplateBuilder_Filters(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Summary").
+		AddRow().
+		AddCell().WithValue(`{{total | currency "USD"}}`).Done().
+		AddCell().WithValue(`{{debt | currency "eur"}}`).Done().
+		AddCell().WithValue(`Due {{date | format "2006-01-02"}}`).Done().
+		AddCell().WithValue("{{customer.Name | upper}}").Done().
+		AddCell().WithValue(`{{ratio | format "%.1f%%"}}`).Done().
+		AddCell().WithValue(`{{note | default "n/a" | upper}}`).Done().
+		AddCell().WithValue(`{{total | currency "CHF"}}`).Done().
+		AddCell().WithValue(`{{shipped | format "02 Jan 2006"}}`).Done().
+		Done()
+
+	tb.ProcessTemplate(map[string]interface{}{
+		"total":    1234567.5,
+		"debt":     -42,
+		"date":     time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		"customer": templateCustomer{Name: "Ada"},
+		"ratio":    12.345,
+		"note":     "",
+		"shipped":  "2024-03-05",
+	})
+
+	rows, err := tb.Build().GetRows("Summary")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"$1,234,567.50", "-€42.00", "Due 2024-02-29", "ADA", "12.3%", "N/A", "CHF 1,234,567.50", "05 Mar 2024"}, rows[0])
+}
+
+func TestTemplateBuilder_FormatFilterNotADate(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Summary").
+		AddRow().
+		AddCell().WithValue(`Due {{date | format "2006-01-02"}}`).Done().
+		Done()
+
+	tb.ProcessTemplate(map[string]interface{}{"date": "soon"})
+
+	value, err := tb.GetCellValue("Summary", "A1")
+	require.NoError(t, err)
+	assert.Equal(t, `Due {{date | format "2006-01-02"}}`, value, "A layout should not be used as a fmt verb")
+
+	errs := tb.Errors()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "cannot format soon with date layout")
+}
+
+func TestTemplateBuilder_RegisterFilter(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.RegisterFilter("initials", func(value interface{}, args ...string) (interface{}, error) {
+		var initials string
+		for _, word := range strings.Fields(fmt.Sprintf("%v", value)) {
+			initials += word[:1] + strings.Join(args, "")
+		}
+		return initials, nil
+	}).RegisterFilter("upper", func(value interface{}, args ...string) (interface{}, error) {
+		return "overridden", nil
+	})
+	tb.AddSheet("Sheet").
+		AddRow().
+		AddCell().WithValue(`{{name | initials "."}}`).Done().
+		AddCell().WithValue("{{name | upper}}").Done().
+		AddCell().WithValue("{{name | unknown}}").Done().
+		Done()
+
+	tb.ProcessTemplate(map[string]interface{}{"name": "Grace Brewster Hopper"})
+
+	rows, err := tb.Build().GetRows("Sheet")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"G.B.H.", "overridden", "{{name | unknown}}"}, rows[0])
+
+	errs := tb.Errors()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "unknown filter 'unknown'")
+}
+
+func TestTemplateBuilder_FilterErrors(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.RegisterFilter("fail", func(value interface{}, args ...string) (interface{}, error) {
+		return nil, fmt.Errorf("no way")
+	})
+	tb.AddSheet("Sheet").
+		AddRow().
+		AddCell().WithValue("{{name | nosuch}}").Done().
+		AddCell().WithValue("Hi {{name | fail}}").Done().
+		Done()
+
+	tb.ProcessTemplate(map[string]interface{}{"name": "Ada"})
+
+	rows, err := tb.Build().GetRows("Sheet")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"{{name | nosuch}}", "Hi {{name | fail}}"}, rows[0])
+	errs := tb.Errors()
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error(), "unknown filter 'nosuch'")
+	assert.Contains(t, errs[1].Error(), "filter 'fail' in 'name | fail': no way")
+}
+
+func TestTemplateBuilder_EachLoop_NestedPath(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Orders").
+		AddRow().AddCell().WithValue("{{#each order.lines}}{{this.sku | lower}}{{/each}}").Done().Done()
+	tb.ProcessTemplate(map[string]interface{}{
+		"order": map[string]interface{}{
+			"lines": []map[string]string{{"sku": "A-1"}, {"sku": "B-2"}},
+		},
+	})
+
+	rows, err := tb.Build().GetRows("Orders")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a-1"}, {"b-2"}}, rows)
+}
+
+func TestTemplateBuilder_PlaceholdersOutsideCells(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Sales {{year}}").
+		AddRow().
+		AddCell().WithValue("Month").Done().
+		AddCell().WithValue("Sales").Done().
+		Done().
+		AddRow().
+		AddCell().WithValue("Jan").Done().
+		AddCell().WithValue(100).Done().
+		Done()
+	sheet := "Sales {{year}}"
+	file := tb.Build()
+
+	require.NoError(t, file.SetHeaderFooter(sheet, &excelize.HeaderFooterOptions{
+		OddHeader: "&C{{company}}",
+		OddFooter: "&RPage &P of {{year}}",
+	}))
+	require.NoError(t, file.AddComment(sheet, excelize.Comment{
+		Cell:   "B2",
+		Author: "Finance",
+		Text:   "Reviewed by {{reviewer}}",
+	}))
+	prompt, promptTitle := "Enter sales for {{company}}", "{{year}} figures"
+	require.NoError(t, file.AddDataValidation(sheet, &excelize.DataValidation{
+		Sqref:            "B2:B13",
+		Type:             "decimal",
+		Operator:         "greaterThanOrEqual",
+		Formula1:         "0",
+		ShowInputMessage: true,
+		Prompt:           &prompt,
+		PromptTitle:      &promptTitle,
+	}))
+	require.NoError(t, file.SetDefinedName(&excelize.DefinedName{
+		Name:     "CompanyName",
+		RefersTo: `="{{company}}"`,
+	}))
+	require.NoError(t, file.AddChart(sheet, "D2", &excelize.Chart{
+		Type:   excelize.Col,
+		Series: []excelize.ChartSeries{{Name: "Sales", Categories: "'Sales {{year}}'!$A$2", Values: "'Sales {{year}}'!$B$2"}},
+		Title:  []excelize.RichTextRun{{Text: "{{company}} & partners {{year}}"}},
+	}))
+
+	_, err := file.NewSheet("Summary")
+	require.NoError(t, err)
+	require.NoError(t, file.SetCellFormula("Summary", "A1", "SUM('Sales {{year}}'!B2:B13)"))
+
+	tb.ProcessTemplate(map[string]interface{}{
+		"year":     2024,
+		"company":  "Acme",
+		"reviewer": "Ada",
+	})
+
+	assert.Equal(t, []string{"Sales 2024", "Summary"}, file.GetSheetList())
+	sheet = "Sales 2024"
+
+	formula, err := file.GetCellFormula("Summary", "A1")
+	require.NoError(t, err)
+	assert.Equal(t, "SUM('Sales 2024'!B2:B13)", formula, "References to renamed sheets should follow")
+
+	headerFooter, err := file.GetHeaderFooter(sheet)
+	require.NoError(t, err)
+	assert.Equal(t, "&CAcme", headerFooter.OddHeader)
+	assert.Equal(t, "&RPage &P of 2024", headerFooter.OddFooter)
+
+	comments, err := file.GetComments(sheet)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "B2", comments[0].Cell)
+	assert.Equal(t, "Finance", comments[0].Author)
+	assert.Equal(t, "Reviewed by Ada", comments[0].Text)
+
+	validations, err := file.GetDataValidations(sheet)
+	require.NoError(t, err)
+	require.Len(t, validations, 1)
+	assert.Equal(t, "Enter sales for Acme", *validations[0].Prompt)
+	assert.Equal(t, "2024 figures", *validations[0].PromptTitle)
+	assert.Equal(t, "B2:B13", validations[0].Sqref)
+
+	names := file.GetDefinedName()
+	require.Len(t, names, 1)
+	assert.Equal(t, `="Acme"`, names[0].RefersTo)
+
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+	chart := readZipEntry(t, buf.Bytes(), "xl/charts/chart1.xml")
+	assert.Contains(t, chart, "<a:t>Acme &amp; partners 2024</a:t>")
+	assert.Contains(t, chart, "<f>&#39;Sales 2024&#39;!$B$2</f>")
+}
+
+// rewriteZip returns a copy of a workbook package whose parts were renamed
+// and changed by rewrite
+func rewriteZip(t *testing.T, data []byte, rewrite func(name string, content []byte) (string, []byte)) []byte {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		name, content := rewrite(f.Name, content)
+		entry, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = entry.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestTemplateBuilder_ChartTitlesThroughRelationships(t *testing.T) {
+	file := excelize.NewFile()
+	require.NoError(t, file.SetSheetName("Sheet1", "Data {{year}}"))
+	require.NoError(t, file.AddChart("Data {{year}}", "D2", &excelize.Chart{
+		Type:   excelize.Col,
+		Series: []excelize.ChartSeries{{Name: "Sales", Categories: "'Data {{year}}'!$A$2", Values: "'Data {{year}}'!$B$2"}},
+		Title:  []excelize.RichTextRun{{Text: "Sales {{year}} by {{region}}"}},
+	}))
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+
+	// Charts are found through relationships, whatever their part name, and
+	// Excel may split a title into several runs
+	template := rewriteZip(t, buf.Bytes(), func(name string, content []byte) (string, []byte) {
+		content = bytes.ReplaceAll(content, []byte("chart1.xml"), []byte("sales.xml"))
+		content = bytes.ReplaceAll(content, []byte("<a:t>Sales {{year}} by {{region}}</a:t>"),
+			[]byte("<a:t>Sales {{ye</a:t></a:r><a:r><a:rPr b=\"true\"></a:rPr><a:t>ar}} by {{region}}</a:t>"))
+		return strings.ReplaceAll(name, "chart1.xml", "sales.xml"), content
+	})
+	tb, err := excelbuilder.LoadTemplate(bytes.NewReader(template))
+	require.NoError(t, err)
+	tb.ProcessTemplate(map[string]interface{}{"year": 2024, "region": "EU"})
+	assert.Empty(t, tb.Errors())
+
+	out, err := tb.Build().WriteToBuffer()
+	require.NoError(t, err)
+	chart := readZipEntry(t, out.Bytes(), "xl/charts/sales.xml")
+	assert.Contains(t, chart, "<a:t>Sales 2024 by EU</a:t>", "A placeholder split across runs should be substituted")
+	assert.Contains(t, chart, "&#39;Data 2024&#39;!$B$2")
+	assert.NotContains(t, chart, "{{")
+}
+
+func TestTemplateBuilder_InvalidSheetNameKept(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Report {{period}}").
+		AddRow().AddCell().WithValue("x").Done().Done()
+
+	tb.ProcessTemplate(map[string]interface{}{"period": "2024/Q1"})
+	assert.Equal(t, []string{"Report {{period}}"}, tb.Build().GetSheetList())
+}
+
+func TestTemplateBuilder_RepeatSheet(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Cover").
+		AddRow().AddCell().WithValue("{{title}}").Done().Done()
+	tb.AddSheet("Region").
+		AddRow().
+		AddCell().WithValue("Region {{name}}").Done().
+		AddCell().WithValue("{{title}}").Done().
+		Done().
+		AddRow().
+		AddCell().WithValue("{{#each stores}}{{this}}{{/each}}").Done().
+		Done()
+	tb.AddSheet("Notes")
+
+	file := tb.Build()
+	require.NoError(t, file.SetColWidth("Region", "A", "A", 32))
+	require.NoError(t, file.MergeCell("Region", "D1", "E1"))
+	landscape := "landscape"
+	require.NoError(t, file.SetPageLayout("Region", &excelize.PageLayoutOptions{Orientation: &landscape}))
+	require.NoError(t, file.SetDefinedName(&excelize.DefinedName{
+		Name:     "_xlnm.Print_Area",
+		RefersTo: "Region!$A$1:$C$10",
+		Scope:    "Region",
+	}))
+	bold, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	require.NoError(t, err)
+	require.NoError(t, file.SetCellStyle("Region", "A1", "A1", bold))
+
+	type region struct {
+		Name   string
+		Stores []string
+	}
+	tb.RepeatSheet("Region", []region{
+		{"North", []string{"Oslo", "Bergen"}},
+		{"South", []string{"Rome"}},
+	}, func(index int, item interface{}) string {
+		return item.(region).Name
+	})
+	tb.ProcessTemplate(map[string]interface{}{"title": "Q1 Review"})
+
+	assert.Equal(t, []string{"Cover", "North", "South", "Notes"}, file.GetSheetList())
+
+	rows, err := file.GetRows("North")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"Region North", "Q1 Review"}, {"Oslo"}, {"Bergen"}}, rows)
+	rows, err = file.GetRows("South")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"Region South", "Q1 Review"}, {"Rome"}}, rows)
+
+	for _, sheet := range []string{"North", "South"} {
+		width, err := file.GetColWidth(sheet, "A")
+		require.NoError(t, err)
+		assert.Equal(t, 32.0, width, sheet)
+
+		merges, err := file.GetMergeCells(sheet)
+		require.NoError(t, err)
+		require.Len(t, merges, 1, sheet)
+		assert.Equal(t, "D1", merges[0].GetStartAxis())
+
+		style, err := file.GetCellStyle(sheet, "A1")
+		require.NoError(t, err)
+		assert.Equal(t, bold, style, sheet)
+
+		layout, err := file.GetPageLayout(sheet)
+		require.NoError(t, err)
+		require.NotNil(t, layout.Orientation, sheet)
+		assert.Equal(t, "landscape", *layout.Orientation, sheet)
+	}
+
+	var printAreas []excelize.DefinedName
+	for _, name := range file.GetDefinedName() {
+		if name.Name == "_xlnm.Print_Area" {
+			printAreas = append(printAreas, name)
+		}
+	}
+	assert.ElementsMatch(t, []excelize.DefinedName{
+		{Name: "_xlnm.Print_Area", RefersTo: "North!$A$1:$C$11", Scope: "North"},
+		{Name: "_xlnm.Print_Area", RefersTo: "South!$A$1:$C$10", Scope: "South"},
+	}, printAreas)
+}
+
+func TestTemplateBuilder_RepeatSheet_InvalidNames(t *testing.T) {
+	for name, names := range map[string][]string{
+		"duplicate":      {"North", "North"},
+		"existing sheet": {"Cover"},
+		"invalid":        {"North/South"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tb := excelbuilder.NewTemplateBuilder()
+			tb.AddSheet("Cover")
+			tb.AddSheet("Region").AddRow().AddCell().WithValue("{{this}}").Done().Done()
+
+			tb.RepeatSheet("Region", names, func(index int, item interface{}) string {
+				return item.(string)
+			})
+			if assert.Len(t, tb.Errors(), 1) {
+				assert.Contains(t, tb.Errors()[0].Error(), "failed to repeat sheet Region")
+			}
+			assert.Equal(t, []string{"Cover", "Region"}, tb.Build().GetSheetList(), "Workbook should be left unchanged")
+		})
+	}
+}
+
+// pngImage returns a PNG image of the given size
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestTemplateBuilder_ImagePlaceholder(t *testing.T) {
+	logo := pngImage(t, 200, 100)
+	path := filepath.Join(t.TempDir(), "stamp.png")
+	require.NoError(t, os.WriteFile(path, pngImage(t, 40, 40), 0o644))
+
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Quote").
+		AddRow().
+		AddCell().WithValue("{{image:logo}}").Done().
+		AddCell().WithValue("").Done().
+		AddCell().WithValue("").Done().
+		AddCell().WithValue("{{ image:stamp }}").Done().
+		AddCell().WithValue("{{image:missing}}").Done().
+		Done()
+	file := tb.Build()
+	require.NoError(t, file.MergeCell("Quote", "A1", "C3"))
+
+	tb.ProcessTemplate(map[string]interface{}{"logo": logo, "stamp": path})
+
+	pictures, err := file.GetPictures("Quote", "A1")
+	require.NoError(t, err)
+	require.Len(t, pictures, 1)
+	assert.Equal(t, logo, pictures[0].File)
+
+	pictures, err = file.GetPictures("Quote", "D1")
+	require.NoError(t, err)
+	require.Len(t, pictures, 1)
+	assert.Equal(t, ".png", pictures[0].Extension)
+
+	for cell, expected := range map[string]string{"A1": "", "D1": "", "E1": "{{image:missing}}"} {
+		value, err := file.GetCellValue("Quote", cell)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value, cell)
+	}
+
+	// The wide logo fills the height of the merged range A1:C3 but not its width
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+	drawing := readZipEntry(t, buf.Bytes(), "xl/drawings/drawing1.xml")
+	assert.Contains(t, drawing, `<xdr:to><xdr:col>1</xdr:col><xdr:colOff>419100</xdr:colOff><xdr:row>3</xdr:row><xdr:rowOff>0</xdr:rowOff></xdr:to>`)
+}
+
+func TestTemplateBuilder_ImagePlaceholder_Invalid(t *testing.T) {
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Quote").
+		AddRow().
+		AddCell().WithValue("{{image:logo}}").Done().
+		AddCell().WithValue("{{image:count}}").Done().
+		Done()
+	tb.ProcessTemplate(map[string]interface{}{"logo": []byte("not an image"), "count": 3})
+
+	pictures, err := tb.Build().GetPictures("Quote", "A1")
+	require.NoError(t, err)
+	assert.Empty(t, pictures)
+	value, err := tb.GetCellValue("Quote", "B1")
+	require.NoError(t, err)
+	assert.Equal(t, "{{image:count}}", value)
+
+	errs := tb.Errors()
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error(), "image logo in Quote!A1: unsupported image format")
+	assert.Contains(t, errs[1].Error(), "image count in Quote!B1: expected []byte or file path, got int")
+}
+
+func TestTemplateBuilder_ImagePlaceholder_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.png")
+
+	tb := excelbuilder.NewTemplateBuilder()
+	tb.AddSheet("Quote").AddRow().AddCell().WithValue("{{image:logo}}").Done().Done()
+	tb.ProcessTemplate(map[string]interface{}{"logo": path})
+
+	pictures, err := tb.Build().GetPictures("Quote", "A1")
+	require.NoError(t, err)
+	assert.Empty(t, pictures)
+	value, err := tb.GetCellValue("Quote", "A1")
+	require.NoError(t, err)
+	assert.Equal(t, "{{image:logo}}", value, "Placeholder should be kept")
+
+	errs := tb.Errors()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "image logo in Quote!A1")
+	assert.ErrorIs(t, errs[0], os.ErrNotExist)
+}
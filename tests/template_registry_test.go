@@ -0,0 +1,186 @@
+package excelbuilder_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// workbookBytes returns the content of a workbook with the given cells on Sheet1
+func workbookBytes(t *testing.T, cells map[string]string) []byte {
+	t.Helper()
+	file := excelize.NewFile()
+	for cellRef, value := range cells {
+		require.NoError(t, file.SetCellValue("Sheet1", cellRef, value))
+	}
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func newTemplateFS(t *testing.T) fstest.MapFS {
+	t.Helper()
+	invoice := map[string]string{
+		"A1": "Invoice for {{customer.name}}",
+		"A2": "{{note | default \"-\"}}",
+		"A3": "{{#each items}}{{sku}}",
+		"B3": "{{qty}}{{/each}}",
+		"A4": "{{total | currency \"USD\"}}",
+	}
+	return fstest.MapFS{
+		"invoices/invoice.meta.yaml": {Data: []byte("name: invoice\nversion: \"1.2\"\nfile: invoice-v1.xlsx\n")},
+		"invoices/invoice-v1.xlsx":   {Data: workbookBytes(t, map[string]string{"A1": "Old {{customer.name}}"})},
+		"invoices/invoice-v10.json":  {Data: []byte(`{"name": "invoice", "version": "1.10", "description": "Current layout"}`)},
+		"invoices/invoice-v10.xlsx":  {Data: workbookBytes(t, invoice)},
+		"reports/summary.xlsx":       {Data: workbookBytes(t, map[string]string{"A1": "{{period}}"})},
+		"reports/~$summary.xlsx":     {Data: []byte("lock file")},
+		"reports/strict.meta.yml":    {Data: []byte("file: summary.xlsx\nversion: \"2\"\nrequired: [period, owner]\n")},
+		"reports/readme.txt":         {Data: []byte("not a template")},
+		"reports/settings.json":      {Data: []byte(`{"theme": "dark"}`)},
+		"reports/sample-data.yaml":   {Data: []byte("period: 2024-Q1\n")},
+	}
+}
+
+func TestTemplateRegistry_LoadsTemplatesByNameAndVersion(t *testing.T) {
+	registry, err := excelbuilder.NewTemplateRegistry(newTemplateFS(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"invoice", "strict"}, registry.Names())
+	assert.Equal(t, []string{"1.2", "1.10"}, registry.Versions("invoice"))
+
+	latest, err := registry.Get("invoice", "")
+	require.NoError(t, err)
+	assert.Equal(t, "1.10", latest.Metadata.Version)
+	assert.Equal(t, "Current layout", latest.Metadata.Description)
+	assert.Equal(t, "invoices/invoice-v10.xlsx", latest.Metadata.File)
+
+	old, err := registry.Get("invoice", "1.2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"customer.name"}, old.Placeholders())
+
+	_, err = registry.Get("invoice", "3")
+	assert.Error(t, err)
+	_, err = registry.Get("missing", "")
+	assert.Error(t, err)
+}
+
+func TestTemplateRegistry_UndescribedWorkbooks(t *testing.T) {
+	fsys := fstest.MapFS{
+		"summary.xlsx": {Data: workbookBytes(t, map[string]string{"A1": "{{period}}"})},
+	}
+	registry, err := excelbuilder.NewTemplateRegistry(fsys)
+	require.NoError(t, err)
+
+	summary, err := registry.Get("summary", "")
+	require.NoError(t, err)
+	assert.Equal(t, "", summary.Metadata.Version)
+	assert.Equal(t, []string{"period"}, summary.Placeholders())
+}
+
+func TestTemplateRegistry_CheckData(t *testing.T) {
+	registry, err := excelbuilder.NewTemplateRegistry(newTemplateFS(t))
+	require.NoError(t, err)
+	invoice, err := registry.Get("invoice", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"customer.name", "items", "note", "qty", "sku", "total"}, invoice.Placeholders())
+
+	report := invoice.CheckData(map[string]interface{}{
+		"customer": map[string]interface{}{"city": "Hanoi"},
+		"items":    []map[string]interface{}{},
+		"debug":    true,
+	})
+	assert.Equal(t, []string{"customer.name", "total"}, report.Missing)
+	assert.Equal(t, []string{"debug"}, report.Unused)
+
+	strict, err := registry.Get("strict", "2")
+	require.NoError(t, err)
+	report = strict.CheckData(map[string]interface{}{"period": "Q1"})
+	assert.Equal(t, []string{"owner"}, report.Missing)
+	assert.Empty(t, report.Unused)
+}
+
+func TestTemplateRegistry_Render(t *testing.T) {
+	registry, err := excelbuilder.NewTemplateRegistry(newTemplateFS(t))
+	require.NoError(t, err)
+	invoice, err := registry.Get("invoice", "")
+	require.NoError(t, err)
+
+	_, err = invoice.Render(map[string]interface{}{"total": 5})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing data for customer.name")
+
+	data := map[string]interface{}{
+		"customer": map[string]interface{}{"name": "Acme"},
+		"items":    []map[string]interface{}{{"sku": "A-1", "qty": 2}, {"sku": "B-2", "qty": 1}},
+		"total":    1234.5,
+	}
+	tb, err := invoice.Render(data)
+	require.NoError(t, err)
+	rows, err := tb.Build().GetRows("Sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Invoice for Acme"},
+		{"-"},
+		{"A-1", "2"},
+		{"B-2", "1"},
+		{"$1,234.50"},
+	}, rows)
+
+	// Each render starts from an unprocessed copy of the template
+	again, err := invoice.Open()
+	require.NoError(t, err)
+	value, err := again.GetCellValue("Sheet1", "A1")
+	require.NoError(t, err)
+	assert.Equal(t, "Invoice for {{customer.name}}", value)
+}
+
+func TestTemplateRegistry_RenderProcessingErrors(t *testing.T) {
+	registry, err := excelbuilder.NewTemplateRegistry(fstest.MapFS{
+		"loop.xlsx":   {Data: workbookBytes(t, map[string]string{"A1": "{{#each items}}{{sku}}"})},
+		"filter.xlsx": {Data: workbookBytes(t, map[string]string{"A1": "{{name | shout}}"})},
+	})
+	require.NoError(t, err)
+
+	loop, err := registry.Get("loop", "")
+	require.NoError(t, err)
+	_, err = loop.Render(map[string]interface{}{"items": []string{"a"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "template loop:")
+
+	filter, err := registry.Get("filter", "")
+	require.NoError(t, err)
+	_, err = filter.Render(map[string]interface{}{"name": "Acme"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "shout")
+}
+
+func TestTemplateRegistry_Errors(t *testing.T) {
+	_, err := excelbuilder.NewTemplateRegistry(fstest.MapFS{
+		"a.yaml":      {Data: []byte("name: report\nfile: a.xlsx\n")},
+		"b.meta.yaml": {Data: []byte("name: report\nfile: a.xlsx\n")},
+		"a.xlsx":      {Data: workbookBytes(t, nil)},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "defined by both")
+
+	_, err = excelbuilder.NewTemplateRegistry(fstest.MapFS{
+		"broken.meta.yaml": {Data: []byte("name: broken\nfiel: x.xlsx\n")},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid template metadata")
+
+	_, err = excelbuilder.NewTemplateRegistry(fstest.MapFS{
+		"missing.meta.yaml": {Data: []byte("name: missing\n")},
+	})
+	assert.Error(t, err)
+
+	_, err = excelbuilder.NewTemplateRegistry(fstest.MapFS{
+		"corrupt.xlsx": {Data: []byte("not a workbook")},
+	})
+	assert.Error(t, err)
+}
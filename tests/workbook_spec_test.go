@@ -0,0 +1,185 @@
+package excelbuilder_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kreddevils18/go-excelbuilder/pkg/excelbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const salesSpecYAML = `
+name: Sales report
+description: Monthly sales
+variables:
+  year: 2023
+styles:
+  header:
+    font: {bold: true, size: 12}
+    fill: {type: pattern, color: "#DDEBF7"}
+  money:
+    numberFormat: "#,##0.00"
+sheets:
+  - name: "Sales {{year}}"
+    data: orders
+    headerStyle: header
+    columns:
+      - {header: Customer, value: customer.name, width: 24}
+      - {header: Region, value: "region | upper"}
+      - {header: Quantity, value: qty}
+      - {header: Amount, value: amount, style: money}
+      - {header: Total, formula: "C{{@row}}*D{{@row}}", style: money}
+    validations:
+      - column: Quantity
+        type: whole
+        operator: greaterThanOrEqual
+        formula1: ["0"]
+    charts:
+      - type: col
+        title: "Sales {{year}}"
+        position: H2
+        dataSeries:
+          - {name: Amount, categories: Customer, values: Amount}
+  - name: Notes
+    headers: [Note]
+`
+
+func salesSpecData() map[string]interface{} {
+	return map[string]interface{}{
+		"orders": []map[string]interface{}{
+			{"customer": map[string]interface{}{"name": "Acme"}, "region": "north", "qty": 2, "amount": 10.5},
+			{"customer": map[string]interface{}{"name": "Globex"}, "region": "south", "qty": 3, "amount": 20.25},
+		},
+	}
+}
+
+func TestWorkbookSpec_LoadAndRenderYAML(t *testing.T) {
+	spec, err := excelbuilder.LoadWorkbookSpec(strings.NewReader(salesSpecYAML))
+	require.NoError(t, err)
+	require.Len(t, spec.Sheets, 2)
+	assert.Equal(t, "Sales report", spec.Name)
+	assert.True(t, spec.Styles["header"].Font.Bold)
+	assert.Equal(t, "#,##0.00", spec.Styles["money"].NumberFormat)
+	assert.Equal(t, 24.0, spec.Sheets[0].Columns[0].Width)
+
+	file, err := excelbuilder.Render(spec, salesSpecData())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Sales 2023", "Notes"}, file.GetSheetList())
+
+	rows, err := file.GetRows("Sales 2023")
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"Customer", "Region", "Quantity", "Amount", "Total"}, rows[0])
+	assert.Equal(t, []string{"Acme", "NORTH", "2", "10.50"}, rows[1][:4])
+	assert.Equal(t, []string{"Globex", "SOUTH", "3", "20.25"}, rows[2][:4])
+
+	formula, err := file.GetCellFormula("Sales 2023", "E3")
+	require.NoError(t, err)
+	assert.Equal(t, "C3*D3", formula)
+	total, err := file.CalcCellValue("Sales 2023", "E3")
+	require.NoError(t, err)
+	assert.Equal(t, "60.75", total)
+
+	headerStyle, err := file.GetCellStyle("Sales 2023", "A1")
+	require.NoError(t, err)
+	style, err := file.GetStyle(headerStyle)
+	require.NoError(t, err)
+	require.NotNil(t, style.Font)
+	assert.True(t, style.Font.Bold)
+
+	width, err := file.GetColWidth("Sales 2023", "A")
+	require.NoError(t, err)
+	assert.Equal(t, 24.0, width)
+
+	validations, err := file.GetDataValidations("Sales 2023")
+	require.NoError(t, err)
+	require.Len(t, validations, 1)
+	assert.Equal(t, "C2:C3", validations[0].Sqref)
+
+	buf, err := file.WriteToBuffer()
+	require.NoError(t, err)
+	chart := readZipEntry(t, buf.Bytes(), "xl/charts/chart1.xml")
+	assert.Contains(t, chart, "Sales 2023&#39;!$A$2:$A$3")
+	assert.Contains(t, chart, "Sales 2023&#39;!$D$2:$D$3")
+	assert.Contains(t, chart, "Sales 2023&#39;!$D$1")
+	assert.Contains(t, readZipEntry(t, buf.Bytes(), "xl/drawings/drawing1.xml"), "<xdr:col>7</xdr:col>")
+
+	notes, err := file.GetRows("Notes")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"Note"}}, notes)
+}
+
+func TestWorkbookSpec_LoadJSON(t *testing.T) {
+	spec, err := excelbuilder.LoadWorkbookSpec(strings.NewReader(`{
+		"Sheets": [{"Name": "People", "Data": "people", "Headers": ["name", "age"]}]
+	}`))
+	require.NoError(t, err)
+
+	file, err := excelbuilder.Render(spec, map[string]interface{}{
+		"people": []map[string]interface{}{{"name": "Ann", "age": 31}},
+	})
+	require.NoError(t, err)
+	rows, err := file.GetRows("People")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "age"}, {"Ann", "31"}}, rows)
+}
+
+func TestWorkbookSpec_VariablesAreDefaults(t *testing.T) {
+	spec := &excelbuilder.TemplateConfig{
+		Variables: map[string]interface{}{"title": "Default"},
+		Sheets: []excelbuilder.SheetTemplate{{
+			Name:    "{{title}}",
+			Headers: []string{"{{title}} header"},
+		}},
+	}
+
+	file, err := excelbuilder.Render(spec, map[string]interface{}{"title": "Override"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Override"}, file.GetSheetList())
+
+	value, err := file.GetCellValue("Override", "A1")
+	require.NoError(t, err)
+	assert.Equal(t, "Override header", value)
+}
+
+func TestWorkbookSpec_InvalidSpecs(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{"empty", ``, "empty"},
+		{"no sheets", `name: Empty`, "at least one sheet"},
+		{"unknown key", "sheets:\n  - name: A\n    colums: []", "unknown field"},
+		{"unknown style", "sheets:\n  - name: A\n    headerStyle: bold\n    headers: [x]", "unknown header style 'bold'"},
+		{"unknown column", "sheets:\n  - name: A\n    headers: [x]\n    validations: [{column: y, type: list}]", "unknown column 'y'"},
+		{"chart without series", "sheets:\n  - name: A\n    charts: [{type: line}]", "data series"},
+		{"malformed", "sheets: [", "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := excelbuilder.LoadWorkbookSpec(bytes.NewBufferString(tt.spec))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestWorkbookSpec_RenderErrors(t *testing.T) {
+	spec, err := excelbuilder.LoadWorkbookSpec(strings.NewReader(salesSpecYAML))
+	require.NoError(t, err)
+
+	_, err = excelbuilder.Render(spec, map[string]interface{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "data 'orders' not found")
+
+	_, err = excelbuilder.Render(spec, map[string]interface{}{"orders": "not a list"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a slice")
+
+	_, err = excelbuilder.Render(nil, nil)
+	assert.Error(t, err)
+}